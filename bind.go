@@ -0,0 +1,227 @@
+package gotopt
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NewParserFor returns a new parser with an option registered for each of
+// the exported fields of the struct to which v points. The options are bound
+// to the fields, so the fields hold the parsed values once a ParseAll
+// operation completes.
+//
+// A field's option is described by the following struct tags:
+//
+//     gotopt  The short option, long name, and argument type of the option,
+//             separated by commas, ex. "t,time,required_argument". Any of
+//             the three parts may be omitted. The default long name is the
+//             field name in kebab-case, and the default argument type is
+//             no_argument for bool fields and required_argument for all
+//             others. The types optional_argument and no_argument may also
+//             be used. If an optional argument is omitted, a bool field is
+//             set to true and any other field keeps its value. A value of
+//             "-" causes the field to be ignored.
+//     arg     The text used for the option's argument in the usage output.
+//     usage   The option's description.
+//
+// Fields may be of type bool, string, int, int64, uint, float64,
+// time.Duration, or any type whose pointer implements Value. The non-zero
+// values of the fields are shown in the usage output as the options'
+// defaults.
+//
+// The fields of a nested struct are registered as options as well. Their
+// long names are prefixed with the nested struct's long name, ex. the field
+// Port of the nested struct Server has the long name server-port. The fields
+// of an embedded struct are registered without a prefix.
+func NewParserFor(v interface{}) (Parser, error) {
+	p := NewParser()
+	if err := p.Bind(v); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Bind registers an option for each of the exported fields of the struct to
+// which v points and binds the options to the fields.
+func (p *parser) Bind(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr ||
+		rv.IsNil() ||
+		rv.Elem().Kind() != reflect.Struct {
+
+		return ErrInvalidBindTarget
+	}
+	return p.bindStruct(rv.Elem(), "")
+}
+
+func (p *parser) bindStruct(rv reflect.Value, prefix string) error {
+	rt := rv.Type()
+
+	for x := 0; x < rt.NumField(); x++ {
+		sf := rt.Field(x)
+		fv := rv.Field(x)
+
+		// the fields of unexported, embedded structs are still promoted
+		if sf.PkgPath != "" {
+			if sf.Anonymous && fv.Kind() == reflect.Struct {
+				if err := p.bindStruct(fv, prefix); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		tag := sf.Tag.Get("gotopt")
+		if tag == "-" {
+			continue
+		}
+
+		opt, longName, optType, optTypeOk, err := parseOptTag(tag)
+		if err != nil {
+			return fmt.Errorf("field %s: %v", sf.Name, err)
+		}
+		if longName == "" {
+			longName = toKebabCase(sf.Name)
+		}
+		if prefix != "" {
+			longName = fmt.Sprintf("%s-%s", prefix, longName)
+		}
+
+		value, defOptType, ok := newFieldValue(fv)
+		if !ok {
+			if fv.Kind() != reflect.Struct {
+				return fmt.Errorf(
+					"field %s: unsupported type %s", sf.Name, fv.Type())
+			}
+			if sf.Anonymous {
+				err = p.bindStruct(fv, prefix)
+			} else {
+				// a struct without options, ex. time.Time, is not a group
+				// of options but a value of an unsupported type
+				n := len(p.optsOrdered)
+				err = p.bindStruct(fv, longName)
+				if err == nil && len(p.optsOrdered) == n {
+					err = fmt.Errorf(
+						"field %s: unsupported type %s", sf.Name, fv.Type())
+				}
+			}
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if !optTypeOk {
+			optType = defOptType
+		}
+
+		o := p.opt(
+			opt, longName, optType, sf.Tag.Get("arg"), sf.Tag.Get("usage"))
+		o.value = value
+		if !reflect.DeepEqual(
+			fv.Interface(), reflect.Zero(fv.Type()).Interface()) {
+			o.defValue = value.String()
+		}
+	}
+
+	return nil
+}
+
+// parseOptTag parses the value of a gotopt struct tag.
+func parseOptTag(tag string) (
+	opt int, longName string, optType OptionTypes, optTypeOk bool, err error) {
+
+	parts := strings.Split(tag, ",")
+	if len(parts) > 3 {
+		err = fmt.Errorf("invalid tag %q", tag)
+		return
+	}
+
+	if utf8.RuneCountInString(parts[0]) > 1 {
+		err = fmt.Errorf("invalid short option %q", parts[0])
+		return
+	}
+	if r, size := utf8.DecodeRuneInString(parts[0]); size > 0 {
+		opt = int(r)
+	}
+
+	if len(parts) > 1 {
+		longName = parts[1]
+	}
+
+	if len(parts) > 2 && parts[2] != "" {
+		optTypeOk = true
+		switch parts[2] {
+		case "no_argument":
+			optType = NoArgument
+		case "required_argument":
+			optType = RequiredArgument
+		case "optional_argument":
+			optType = OptionalArgument
+		default:
+			err = fmt.Errorf("invalid argument type %q", parts[2])
+		}
+	}
+
+	return
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// newFieldValue returns a Value bound to the field along with the default
+// argument type for an option bound to the field.
+func newFieldValue(fv reflect.Value) (Value, OptionTypes, bool) {
+	if v, ok := fv.Addr().Interface().(Value); ok {
+		return v, RequiredArgument, true
+	}
+
+	ptr := func(v interface{}) interface{} {
+		return fv.Addr().Convert(reflect.TypeOf(v)).Interface()
+	}
+
+	if fv.Type() == durationType {
+		return (*durationValue)(ptr((*time.Duration)(nil)).(*time.Duration)),
+			RequiredArgument, true
+	}
+
+	switch fv.Kind() {
+	case reflect.Bool:
+		return (*boolValue)(ptr((*bool)(nil)).(*bool)), NoArgument, true
+	case reflect.String:
+		return (*stringValue)(ptr((*string)(nil)).(*string)),
+			RequiredArgument, true
+	case reflect.Int:
+		return (*intValue)(ptr((*int)(nil)).(*int)), RequiredArgument, true
+	case reflect.Int64:
+		return (*int64Value)(ptr((*int64)(nil)).(*int64)),
+			RequiredArgument, true
+	case reflect.Uint:
+		return (*uintValue)(ptr((*uint)(nil)).(*uint)), RequiredArgument, true
+	case reflect.Float64:
+		return (*float64Value)(ptr((*float64)(nil)).(*float64)),
+			RequiredArgument, true
+	}
+
+	return nil, 0, false
+}
+
+// toKebabCase converts a field name such as "HTTPPort" to "http-port".
+func toKebabCase(s string) string {
+	b := &bytes.Buffer{}
+	r := []rune(s)
+	for x, c := range r {
+		if unicode.IsUpper(c) && x > 0 {
+			prevLower := !unicode.IsUpper(r[x-1])
+			nextLower := x+1 < len(r) && unicode.IsLower(r[x+1])
+			if prevLower || nextLower {
+				b.WriteByte('-')
+			}
+		}
+		b.WriteRune(unicode.ToLower(c))
+	}
+	return b.String()
+}
@@ -0,0 +1,137 @@
+package gotopt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testBindServer struct {
+	Host string `usage:"The server host"`
+	Port int    `gotopt:"p" arg:"port" usage:"The server port"`
+}
+
+type testBindCommon struct {
+	Verbose bool `gotopt:"v" usage:"Verbose output"`
+}
+
+type testBindConfig struct {
+	testBindCommon
+	Name    bool           `gotopt:"n,name" usage:"A flag indicating the name is a trailing arg"`
+	Time    time.Duration  `gotopt:"t,time,required_argument" arg:"epoch" usage:"The epoch"`
+	Xist    string         `gotopt:"x,,optional_argument" arg:"val" usage:"A value"`
+	HomeURL string         `usage:"The URL"`
+	List    testListValue  `gotopt:"l" usage:"A list"`
+	Server  testBindServer `gotopt:",srv"`
+	Ignored string         `gotopt:"-"`
+	ignored string
+}
+
+func newTestBindConfig() *testBindConfig {
+	return &testBindConfig{
+		Time:   30 * time.Second,
+		Server: testBindServer{Host: "localhost"},
+	}
+}
+
+func TestBind(t *testing.T) {
+	cfg := newTestBindConfig()
+	p, err := NewParserFor(cfg)
+	assert.NoError(t, err)
+
+	ps, err := p.ParseAll([]string{
+		"tpbind01", "-vnt37s", "-xplay", "--home-url", "http://effie",
+		"-la", "--srv-host=server", "-p", "8080", "-lb", "effie"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"effie"}, ps.Last().Value())
+
+	assert.True(t, cfg.Verbose)
+	assert.True(t, cfg.Name)
+	assert.Equal(t, 37*time.Second, cfg.Time)
+	assert.Equal(t, "play", cfg.Xist)
+	assert.Equal(t, "http://effie", cfg.HomeURL)
+	assert.Equal(t, "a,b", cfg.List.String())
+	assert.Equal(t, "server", cfg.Server.Host)
+	assert.Equal(t, 8080, cfg.Server.Port)
+}
+
+func TestBindOptionalArgument(t *testing.T) {
+	cfg := &struct {
+		Level   int           `gotopt:"l,level,optional_argument"`
+		Timeout time.Duration `gotopt:",,optional_argument"`
+		Debug   bool          `gotopt:"d,debug,optional_argument"`
+	}{Level: 5, Timeout: time.Second}
+	p, err := NewParserFor(cfg)
+	assert.NoError(t, err)
+
+	// the fields keep their values when the arguments are omitted
+	ps, err := p.ParseAll([]string{"tpbind02", "--level", "--timeout", "-d"})
+	assert.NoError(t, err)
+	o := ps.LookupOptLong("level")[0].Value().(Option)
+	assert.Equal(t, "", o.Value())
+	assert.Equal(t, 1, o.Count())
+	assert.Len(t, ps.LookupOptLong("timeout"), 1)
+	assert.Equal(t, 5, cfg.Level)
+	assert.Equal(t, time.Second, cfg.Timeout)
+	assert.True(t, cfg.Debug)
+
+	_, err = p.ParseAll(
+		[]string{"tpbind02", "-l7", "--timeout=3s", "--debug=false"})
+	assert.NoError(t, err)
+	assert.Equal(t, 7, cfg.Level)
+	assert.Equal(t, 3*time.Second, cfg.Timeout)
+	assert.False(t, cfg.Debug)
+}
+
+func TestBindUsage(t *testing.T) {
+	p, err := NewParserFor(newTestBindConfig())
+	assert.NoError(t, err)
+
+	exp := `    -v, --verbose       Verbose output
+    -n, --name          A flag indicating the name is a trailing arg
+    -t, --time epoch    The epoch (default: 30s)
+    -x, --xist [val]    A value
+        --home-url arg  The URL
+    -l, --list arg      A list
+        --srv-host arg  The server host (default: localhost)
+    -p, --srv-port port The server port
+`
+	assert.Equal(t, exp, p.Usage())
+}
+
+func TestBindErrors(t *testing.T) {
+	var s string
+	_, err := NewParserFor(s)
+	assert.Equal(t, ErrInvalidBindTarget, err)
+	_, err = NewParserFor(&s)
+	assert.Equal(t, ErrInvalidBindTarget, err)
+
+	_, err = NewParserFor(&struct {
+		C chan int
+	}{})
+	assert.EqualError(t, err, "field C: unsupported type chan int")
+
+	_, err = NewParserFor(&struct {
+		When time.Time
+	}{})
+	assert.EqualError(t, err, "field When: unsupported type time.Time")
+
+	_, err = NewParserFor(&struct {
+		S string `gotopt:"ab"`
+	}{})
+	assert.EqualError(t, err, `field S: invalid short option "ab"`)
+
+	_, err = NewParserFor(&struct {
+		S string `gotopt:"a,,maybe"`
+	}{})
+	assert.EqualError(t, err, `field S: invalid argument type "maybe"`)
+}
+
+func TestToKebabCase(t *testing.T) {
+	assert.Equal(t, "name", toKebabCase("Name"))
+	assert.Equal(t, "time-out", toKebabCase("TimeOut"))
+	assert.Equal(t, "http-port", toKebabCase("HTTPPort"))
+	assert.Equal(t, "id", toKebabCase("ID"))
+	assert.Equal(t, "server-id", toKebabCase("ServerID"))
+}
@@ -0,0 +1,108 @@
+package gotopt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Choices restricts the option's argument to one of the given choices. An
+// unambiguous abbreviation of a choice is accepted as that choice, the same
+// way GetOptLong accepts abbreviated long names. An argument that is not a
+// choice causes an ErrInvalidChoice to be sent in place of the option.
+func Choices(choices ...string) OptModifier {
+	return func(o *optDef) {
+		o.choices = choices
+	}
+}
+
+// Range restricts the option's argument to a number between min and max,
+// inclusive. An argument outside of the range causes an ErrInvalidArg to be
+// sent in place of the option.
+func Range(min, max float64) OptModifier {
+	return func(o *optDef) {
+		o.rangeMin = min
+		o.rangeMax = max
+		o.hasRange = true
+	}
+}
+
+// Regexp restricts the option's argument to values matched by the regular
+// expression. An argument that is not matched causes an ErrInvalidArg to be
+// sent in place of the option.
+func Regexp(rx *regexp.Regexp) OptModifier {
+	return func(o *optDef) {
+		o.rx = rx
+	}
+}
+
+// checkArg validates the argument against the option's choices, range, and
+// regular expression. The returned argument is the choice the argument
+// abbreviates, if any.
+func (o *optDef) checkArg(arg string) (string, error) {
+	// an omitted, optional argument is not validated
+	if o.optType == NoArgument ||
+		(o.optType == OptionalArgument && arg == "") {
+		return arg, nil
+	}
+
+	if o.counter {
+		n, err := strconv.Atoi(arg)
+		if err == nil && n < 0 {
+			err = fmt.Errorf("count must not be negative")
+		}
+		if err != nil {
+			return arg, &ErrInvalidArg{o.opt, o.longName, arg, err}
+		}
+	}
+
+	if len(o.choices) > 0 {
+		matches := []string{}
+		for _, c := range o.choices {
+			if c == arg {
+				matches = []string{c}
+				break
+			}
+			if strings.HasPrefix(c, arg) {
+				matches = append(matches, c)
+			}
+		}
+		switch {
+		case len(matches) == 1 && arg != "":
+			arg = matches[0]
+		case len(matches) > 1 && arg != "":
+			return arg, &ErrInvalidChoice{
+				o.opt, o.longName, arg, matches, true}
+		default:
+			return arg, &ErrInvalidChoice{
+				o.opt, o.longName, arg, o.choices, false}
+		}
+	}
+
+	if o.hasRange {
+		f, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return arg, &ErrInvalidArg{o.opt, o.longName, arg, err}
+		}
+		if f < o.rangeMin || f > o.rangeMax {
+			return arg, &ErrInvalidArg{o.opt, o.longName, arg, fmt.Errorf(
+				"value out of range %s", o.rangeText())}
+		}
+	}
+
+	if o.rx != nil && !o.rx.MatchString(arg) {
+		return arg, &ErrInvalidArg{o.opt, o.longName, arg, fmt.Errorf(
+			"value does not match %s", o.rx)}
+	}
+
+	return arg, nil
+}
+
+// rangeText returns the option's range as shown in errors and usage text,
+// ex. "0..9".
+func (o *optDef) rangeText() string {
+	return fmt.Sprintf("%s..%s",
+		strconv.FormatFloat(o.rangeMin, 'g', -1, 64),
+		strconv.FormatFloat(o.rangeMax, 'g', -1, 64))
+}
@@ -0,0 +1,87 @@
+package gotopt
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParserChoices(t *testing.T) {
+	var color string
+	p := NewParser()
+	p.StringVar(&color, 'c', "color", "when", "",
+		Choices("auto", "always", "never"))
+	p.Opt('x', "", RequiredArgument, "", "", Choices("a", "ab"))
+
+	ps, err := p.ParseAll([]string{"tpchoices01", "--color=nev", "-xa"})
+	assert.NoError(t, err)
+	ps = ps.First()
+	assert.Equal(t, "never", ps.Value().(Option).Value())
+	assert.Equal(t, "never", color)
+	ps, _ = ps.Next()
+	assert.Equal(t, "a", ps.Value().(Option).Value())
+
+	ps, err = p.ParseAll([]string{"tpchoices01", "-ca", "--color", "blue"})
+	assert.NoError(t, err)
+	ps = ps.First()
+	assert.Equal(t, &ErrInvalidChoice{
+		'c', "color", "a", []string{"auto", "always"}, true}, ps.Value())
+	assert.EqualError(t, ps.Value().(error), "ambiguous arg 'a' for option "+
+		"'--color'; possibilities: 'auto' 'always'")
+
+	ps, _ = ps.Next()
+	assert.IsType(t, &ErrInvalidChoice{}, ps.Value())
+	assert.EqualError(t, ps.Value().(error), "invalid arg 'blue' for option "+
+		"'--color'; valid choices: 'auto' 'always' 'never'")
+	assert.Equal(t, "never", color)
+}
+
+func TestParserRange(t *testing.T) {
+	var level int
+	p := NewParser()
+	p.IntVar(&level, 'l', "level", "", "", Range(0, 9))
+	p.Opt('o', "", OptionalArgument, "", "", Range(0.5, 1))
+
+	ps, err := p.ParseAll([]string{"tprange01", "-l9", "-o", "-o0.75"})
+	assert.NoError(t, err)
+	assert.Len(t, ps.LookupOpt('l'), 1)
+	assert.Len(t, ps.LookupOpt('o'), 2)
+	assert.Equal(t, 9, level)
+
+	ps, err = p.ParseAll([]string{"tprange01", "--level=10", "-lten"})
+	assert.NoError(t, err)
+	ps = ps.First()
+	assert.EqualError(t, ps.Value().(error),
+		"invalid arg '10' for option '--level': value out of range 0..9")
+	ps, _ = ps.Next()
+	assert.IsType(t, &ErrInvalidArg{}, ps.Value())
+	assert.Equal(t, 9, level)
+}
+
+func TestParserRegexp(t *testing.T) {
+	p := NewParser()
+	p.Opt('n', "name", RequiredArgument, "", "",
+		Regexp(regexp.MustCompile(`^[a-z]+$`)))
+
+	ps, err := p.ParseAll([]string{"tpregexp01", "-neffie", "-nEffie"})
+	assert.NoError(t, err)
+	ps = ps.First()
+	assert.Equal(t, "effie", ps.Value().(Option).Value())
+	ps, _ = ps.Next()
+	assert.EqualError(t, ps.Value().(error),
+		"invalid arg 'Effie' for option '--name': value does not match ^[a-z]+$")
+}
+
+func TestPrintUsageChoices(t *testing.T) {
+	p := NewParser()
+	p.Opt('c', "color", RequiredArgument, "when", "when to use color",
+		Choices("auto", "always", "never"), Default("auto"))
+	p.Opt('l', "level", RequiredArgument, "", "the level", Range(0, 9))
+
+	exp := `    -c, --color when when to use color (choices: auto, always, never) (default: auto)
+    -l, --level arg  the level (range: 0..9)
+`
+	p.SetUsageWidth(120)
+	assert.Equal(t, exp, p.Usage())
+}
@@ -0,0 +1,102 @@
+package gotopt
+
+import "unicode/utf8"
+
+// Command is the representation of a command as sent to clients receiving
+// the results of a Parse or ParseAll operation. The options and arguments
+// that follow a Command belong to that command.
+type Command interface {
+	// Name returns the command's name.
+	Name() string
+
+	// Parser returns the command's parser.
+	Parser() Parser
+}
+
+// parsedCmd is a command that's been parsed.
+type parsedCmd struct {
+	p *parser
+}
+
+func (c *parsedCmd) Name() string {
+	return c.p.name
+}
+func (c *parsedCmd) Parser() Parser {
+	return c.p
+}
+func (c *parsedCmd) String() string {
+	return "&{Command:" + c.p.name + "}"
+}
+
+// Command registers a command with the parser.
+func (p *parser) Command(name, usage string) Parser {
+	if name == "" {
+		panic("name invalid")
+	}
+	if _, ok := p.commandsByName[name]; ok {
+		panic("command " + name + " already registered")
+	}
+	cmd := newParser()
+	cmd.name = name
+	cmd.desc = usage
+	cmd.parent = p
+	p.commands = append(p.commands, cmd)
+	p.commandsByName[name] = cmd
+	if n := utf8.RuneCountInString(name); n > p.maxCmdLen {
+		p.maxCmdLen = n
+	}
+	return cmd
+}
+
+// visibleOpts returns the options returned by allOpts that are shown in the
+// usage text, the generated documentation, and the completion candidates.
+func (p *parser) visibleOpts() []*optDef {
+	opts := []*optDef{}
+	for _, o := range p.allOpts() {
+		if !o.hidden && o.enabled() {
+			opts = append(opts, o)
+		}
+	}
+	return opts
+}
+
+// allOpts returns the parser's options followed by the persistent options
+// inherited from the parser's ancestors. An inherited option is omitted if
+// its option character or long name is used by a closer option.
+func (p *parser) allOpts() []*optDef {
+	opts := append([]*optDef{}, p.optsOrdered...)
+	if p.parent == nil {
+		return opts
+	}
+
+	shortOpts := map[int]bool{}
+	longOpts := map[string]bool{}
+	for _, o := range opts {
+		shortOpts[o.opt] = true
+		for _, name := range o.longNames() {
+			longOpts[name] = true
+		}
+	}
+
+	for a := p.parent; a != nil; a = a.parent {
+		for _, o := range a.optsOrdered {
+			if !o.persistent {
+				continue
+			}
+			shadowed := o.opt > 0 && shortOpts[o.opt]
+			for _, name := range o.longNames() {
+				shadowed = shadowed || longOpts[name]
+			}
+			if shadowed {
+				continue
+			}
+			shortOpts[o.opt] = true
+			for _, name := range o.longNames() {
+				longOpts[name] = true
+			}
+			opts = append(opts, o)
+		}
+	}
+
+	return opts
+}
@@ -0,0 +1,174 @@
+package gotopt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParserCommand(t *testing.T) {
+	p := NewParser()
+	p.Opt('v', "verbose", NoArgument, "", "", Persistent())
+	p.Opt('C', "", RequiredArgument, "", "")
+	add := p.Command("remote", "").Command("add", "")
+	add.Opt('f', "fetch", NoArgument, "", "")
+
+	ps, err := p.ParseAll([]string{
+		"tpcmd01", "-C", "/tmp", "remote", "add", "-f", "effie", "-v"})
+	assert.NoError(t, err)
+	ps = ps.First()
+
+	o := ps.Value().(Option)
+	assert.EqualValues(t, 'C', o.Opt())
+	assert.Equal(t, "/tmp", o.Value())
+
+	ps, _ = ps.Next()
+	assert.Implements(t, (*Command)(nil), ps.Value())
+	c := ps.Value().(Command)
+	assert.Equal(t, "remote", c.Name())
+
+	ps, _ = ps.Next()
+	c = ps.Value().(Command)
+	assert.Equal(t, "add", c.Name())
+	assert.Equal(t, add, c.Parser())
+
+	ps, _ = ps.Next()
+	o = ps.Value().(Option)
+	assert.EqualValues(t, 'f', o.Opt())
+
+	ps, _ = ps.Next()
+	o = ps.Value().(Option)
+	assert.EqualValues(t, 'v', o.Opt())
+
+	ps, _ = ps.Next()
+	assert.Equal(t, []string{"effie"}, ps.Value())
+
+	_, ok := ps.Next()
+	assert.False(t, ok)
+	assert.Equal(t, 5, ps.Index())
+	assert.Equal(t, ps, ps.First().Last())
+}
+
+func TestParserCommandRequireOrder(t *testing.T) {
+	p := NewParser()
+	remote := p.Command("remote", "")
+	remote.Opt('n', "dry-run", NoArgument, "", "")
+	remote.Command("add", "").Opt('f', "fetch", NoArgument, "", "")
+
+	// -n belongs to the remote command, and -f belongs to the add command
+	ps, err := p.ParseAll([]string{"tpcmd02", "-n", "remote", "-f"})
+	assert.NoError(t, err)
+	ps = ps.First()
+	assert.IsType(t, &ErrUnknownOpt{}, ps.Value())
+	assert.EqualValues(t, 'n', ps.Value().(*ErrUnknownOpt).Opt)
+
+	ps, _ = ps.Next()
+	assert.Equal(t, "remote", ps.Value().(Command).Name())
+
+	ps, _ = ps.Next()
+	assert.IsType(t, &ErrUnknownOpt{}, ps.Value())
+	assert.EqualValues(t, 'f', ps.Value().(*ErrUnknownOpt).Opt)
+}
+
+func TestParserCommandPersistentNotInherited(t *testing.T) {
+	p := NewParser()
+	p.Opt('C', "", RequiredArgument, "", "")
+	p.Command("status", "")
+
+	ps, err := p.ParseAll([]string{"tpcmd03", "status", "-C", "/tmp"})
+	assert.NoError(t, err)
+	ps = ps.First()
+	assert.Equal(t, "status", ps.Value().(Command).Name())
+
+	ps, _ = ps.Next()
+	assert.IsType(t, &ErrUnknownOpt{}, ps.Value())
+}
+
+func TestParserCommandPersistentEnv(t *testing.T) {
+	defer setTestEnv("GOTOPT_TEST_LEVEL", "3")()
+
+	p := NewParser()
+	p.Opt('l', "level", RequiredArgument, "", "",
+		Env("GOTOPT_TEST_LEVEL"), Persistent())
+	p.Command("run", "Run the thing")
+
+	// the option is given after the command's name
+	r, err := p.ParseResult([]string{"tpcmd05", "run", "-l", "5", "effie"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"5"}, r.All("level"))
+	assert.Equal(t, 1, r.Count("level"))
+	assert.Equal(t, []string{"effie"}, r.Positionals())
+
+	ps, err := p.ParseAll([]string{"tpcmd05", "run", "effie"})
+	assert.NoError(t, err)
+	ps = ps.First()
+	assert.Equal(t, "run", ps.Value().(Command).Name())
+
+	ps, _ = ps.Next()
+	o := ps.Value().(Option)
+	assert.EqualValues(t, 'l', o.Opt())
+	assert.Equal(t, "3", o.Value())
+	assert.Equal(t, FromEnv, o.Source())
+
+	ps, _ = ps.Next()
+	assert.Equal(t, []string{"effie"}, ps.Value())
+}
+
+func TestParserCommandUnknown(t *testing.T) {
+	p := NewParser()
+	p.Opt('v', "verbose", NoArgument, "", "")
+	p.Opt('C', "", RequiredArgument, "", "")
+	p.Command("remote", "")
+
+	ps, err := p.ParseAll([]string{"tpcmd04", "-v", "effie", "-C", "/tmp"})
+	assert.NoError(t, err)
+	ps = ps.First()
+	assert.Implements(t, (*Option)(nil), ps.Value())
+
+	ps, _ = ps.Next()
+	assert.IsType(t, &ErrUnknownCommand{}, ps.Value())
+	assert.EqualError(t, ps.Value().(error), "unknown command 'effie'")
+
+	_, ok := ps.Next()
+	assert.False(t, ok)
+}
+
+func TestParserCommandUsage(t *testing.T) {
+	p := NewParser()
+	p.Opt('v', "verbose", NoArgument, "", "Verbose output", Persistent())
+	p.Opt('C', "", RequiredArgument, "path", "Run as if started in path")
+	remote := p.Command("remote", "Manage the set of remotes")
+	remote.Opt('n', "dry-run", NoArgument, "", "Do not make any changes")
+	add := remote.Command("add", "Add a remote")
+	add.Opt('f', "fetch", NoArgument, "", "Fetch the remote")
+	p.Command("status", "Show the working tree status")
+
+	exp := `    -v, --verbose Verbose output
+    -C path       Run as if started in path
+
+    Commands:
+      remote      Manage the set of remotes
+      status      Show the working tree status
+`
+	assert.Equal(t, exp, p.Usage())
+
+	exp = `    -n, --dry-run Do not make any changes
+    -v, --verbose Verbose output
+
+    Commands:
+      add         Add a remote
+`
+	assert.Equal(t, exp, remote.Usage())
+
+	exp = `    -f, --fetch   Fetch the remote
+    -v, --verbose Verbose output
+`
+	assert.Equal(t, exp, add.Usage())
+}
+
+func TestParserCommandRegisterTwice(t *testing.T) {
+	p := NewParser()
+	p.Command("remote", "")
+	assert.Panics(t, func() { p.Command("remote", "") })
+	assert.Panics(t, func() { p.Command("", "") })
+}
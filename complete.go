@@ -0,0 +1,191 @@
+package gotopt
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CompleteCommand is the name of the hidden command that writes the
+// candidates for completing the last of the arguments that follow it.
+const CompleteCommand = "__complete"
+
+// CompleteFunc returns the candidates for completing an argument that begins
+// with the given prefix.
+type CompleteFunc func(prefix string) []string
+
+// Completer registers a function that returns the candidates for completing
+// the option's argument. Without a Completer, the candidates for the argument
+// of an option restricted with the Choices modifier are the option's choices.
+func Completer(f CompleteFunc) OptModifier {
+	return func(o *optDef) {
+		o.completer = f
+	}
+}
+
+// CompleteArgs registers a function that returns the candidates for
+// completing a non-option argument.
+func (p *parser) CompleteArgs(f CompleteFunc) {
+	p.argCompleter = f
+}
+
+// Complete writes the candidates for completing the last argument of argv if
+// the first argument after the program name is CompleteCommand.
+func (p *parser) Complete(argv []string, w io.Writer) (bool, error) {
+	if len(argv) < 2 || argv[1] != CompleteCommand {
+		return false, nil
+	}
+	for _, c := range p.Completions(argv[2:]) {
+		if _, err := fmt.Fprintln(w, c); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// Completions returns the candidates for completing the last of the words.
+func (p *parser) Completions(words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+
+	// getopt may permute the argument list, so the word being completed is
+	// recorded first
+	cur := words[len(words)-1]
+
+	argv := append([]string{p.progName()}, words...)
+	sc := p.newOptScanner(argv)
+	gop := sc.gop
+
+	for {
+		opt, o := sc.next()
+		if opt == -1 {
+			break
+		}
+
+		// only the result for the word being completed is of interest
+		if gop.OptInd < len(argv) || gop.data.nextChar != nil {
+			continue
+		}
+
+		if o == nil || o.optType == NoArgument {
+			return p.optCompletions(cur)
+		}
+
+		// the argument is the word being completed
+		if optArgvIndex(argv, gop, o) < len(argv)-1 {
+			return o.argCompletions("", gop.OptArg)
+		}
+
+		// the argument is part of the word being completed, ex. -nt37 or
+		// --time=37. an optional argument that is omitted is completed as
+		// if the option were not yet complete.
+		prefix := cur[:len(cur)-len(gop.OptArg)]
+		if gop.OptArg == "" &&
+			o.optType == OptionalArgument &&
+			!strings.HasSuffix(cur, "=") {
+			return p.optCompletions(cur)
+		}
+		return o.argCompletions(prefix, gop.OptArg)
+	}
+
+	// a non-option argument before the word being completed is the name of
+	// a command
+	if len(p.commands) > 0 && gop.OptInd < len(argv)-1 {
+		if cmd, ok := p.commandsByName[argv[gop.OptInd]]; ok {
+			return cmd.Completions(argv[gop.OptInd+1:])
+		}
+		return nil
+	}
+
+	if strings.HasPrefix(cur, "-") && cur != "-" {
+		return p.optCompletions(cur)
+	}
+
+	if len(p.commands) > 0 {
+		names := []string{}
+		for _, c := range p.commands {
+			if strings.HasPrefix(c.name, cur) {
+				names = append(names, c.name)
+			}
+		}
+		return names
+	}
+
+	if p.argCompleter != nil {
+		return p.argCompleter(cur)
+	}
+	return nil
+}
+
+// optCompletions returns the options that begin with the prefix. The long
+// names of options that take an argument are followed by an equals sign.
+func (p *parser) optCompletions(prefix string) []string {
+	names := []string{}
+	for _, o := range p.visibleOpts() {
+		if o.opt > 0 {
+			names = append(names, fmt.Sprintf("-%c", o.opt))
+		}
+		for _, name := range o.longNames() {
+			switch o.optType {
+			case NoArgument:
+				names = append(names, "--"+name)
+				if o.negatable {
+					names = append(names, "--no-"+name)
+				}
+			case RequiredArgument:
+				names = append(names, "--"+name+"=")
+			case OptionalArgument:
+				names = append(names, "--"+name, "--"+name+"=")
+			}
+		}
+	}
+
+	matches := []string{}
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
+
+// argCompletions returns the candidates for the option's argument that begin
+// with arg. Each candidate is preceded by the part of the word being
+// completed that precedes the argument.
+func (o *optDef) argCompletions(prefix, arg string) []string {
+	var vals []string
+	switch {
+	case o.completer != nil:
+		vals = o.completer(arg)
+	case len(o.choices) > 0:
+		for _, c := range o.choices {
+			if strings.HasPrefix(c, arg) {
+				vals = append(vals, c)
+			}
+		}
+	}
+
+	matches := []string{}
+	for _, v := range vals {
+		matches = append(matches, prefix+v)
+	}
+	return matches
+}
+
+// hasCompleters returns a flag indicating whether or not the parser or any
+// of its commands have a registered CompleteFunc.
+func (p *parser) hasCompleters() bool {
+	ok := false
+	p.walk(func(c *parser) {
+		if c.argCompleter != nil {
+			ok = true
+		}
+		for _, o := range c.optsOrdered {
+			if o.completer != nil {
+				ok = true
+			}
+		}
+	})
+	return ok
+}
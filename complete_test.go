@@ -0,0 +1,101 @@
+package gotopt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testClusters is a CompleteFunc that completes the names of clusters.
+func testClusters(prefix string) []string {
+	vals := []string{}
+	for _, c := range []string{"prod", "preview", "dev"} {
+		if strings.HasPrefix(c, prefix) {
+			vals = append(vals, c)
+		}
+	}
+	return vals
+}
+
+func TestParserCompletions(t *testing.T) {
+	p := NewParser()
+	p.Opt('n', "name", NoArgument, "", "", Persistent())
+	p.Opt('t', "time", RequiredArgument, "", "", Completer(testClusters))
+	p.Opt('x', "xist", OptionalArgument, "", "",
+		Choices("auto", "always", "never"))
+	remote := p.Command("remote", "")
+	remote.Opt('f', "fetch", NoArgument, "", "")
+	remote.CompleteArgs(func(prefix string) []string {
+		return []string{prefix + "origin"}
+	})
+	p.Command("run", "")
+
+	tests := []struct {
+		words []string
+		exp   []string
+	}{
+		{[]string{"-t", ""}, []string{"prod", "preview", "dev"}},
+		{[]string{"-t", "p"}, []string{"prod", "preview"}},
+		{[]string{"-nt", "d"}, []string{"dev"}},
+		{[]string{"-ntpr"}, []string{"-ntprod", "-ntpreview"}},
+		{[]string{"--time=d"}, []string{"--time=dev"}},
+		{[]string{"--ti=d"}, []string{"--ti=dev"}},
+		{[]string{"-n", "--time", "pre"}, []string{"preview"}},
+		{[]string{"--xist=a"}, []string{"--xist=auto", "--xist=always"}},
+		{[]string{"-xn"}, []string{"-xnever"}},
+		{[]string{"--xi"}, []string{"--xist", "--xist="}},
+		{[]string{"--"}, []string{"--name", "--time=", "--xist", "--xist="}},
+		{[]string{"-t"}, []string{"-t"}},
+		{[]string{"-"}, []string{}},
+		{[]string{""}, []string{"remote", "run"}},
+		{[]string{}, []string{"remote", "run"}},
+		{[]string{"-n", "r"}, []string{"remote", "run"}},
+		{[]string{"-t", "dev", "re"}, []string{"remote"}},
+		{[]string{"remote", "--"}, []string{"--fetch", "--name"}},
+		{[]string{"remote", "-f", "or"}, []string{"ororigin"}},
+		{[]string{"nope", ""}, nil},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.exp, p.Completions(tt.words), "%q", tt.words)
+	}
+}
+
+func TestParserComplete(t *testing.T) {
+	p := NewParser()
+	p.Opt('t', "time", RequiredArgument, "", "", Completer(testClusters))
+
+	b := &bytes.Buffer{}
+	ok, err := p.Complete([]string{"prog", "--time", "p"}, b)
+	assert.False(t, ok)
+	assert.NoError(t, err)
+	assert.Empty(t, b.String())
+
+	ok, err = p.Complete([]string{"prog", CompleteCommand, "--time", "p"}, b)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, "prod\npreview\n", b.String())
+}
+
+func TestGenerateCompletionDynamic(t *testing.T) {
+	p := newParser()
+	p.name = "prog"
+	p.Opt('t', "time", RequiredArgument, "", "", Completer(testClusters))
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, p.GenerateCompletion("bash", b))
+	assert.Contains(t, b.String(),
+		`done < <("${words[0]}" __complete "${words[@]:1}" "$cur" 2>/dev/null)`)
+
+	b.Reset()
+	assert.NoError(t, p.GenerateCompletion("zsh", b))
+	assert.Contains(t, b.String(), `candidates=(${(f)"$("${words[1]}" `+
+		`__complete "${(@)words[2,CURRENT]}" 2>/dev/null)"})`)
+
+	b.Reset()
+	assert.NoError(t, p.GenerateCompletion("fish", b))
+	assert.Contains(t, b.String(),
+		"complete -c prog -f -a '(__prog_complete)'\n")
+}
@@ -0,0 +1,475 @@
+package gotopt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// GenerateCompletion writes a completion script for the shell to the
+// provided stream. The supported shells are bash, zsh, and fish.
+//
+// If the parser or any of its commands have a CompleteFunc, the script gets
+// its candidates from the program using the CompleteCommand protocol.
+// Otherwise the script is generated from the parser's definitions.
+func (p *parser) GenerateCompletion(shell string, w io.Writer) error {
+	b := &bytes.Buffer{}
+	dynamic := p.hasCompleters()
+	switch {
+	case shell == "bash" && dynamic:
+		p.genBashDynamicCompletion(b)
+	case shell == "bash":
+		p.genBashCompletion(b)
+	case shell == "zsh" && dynamic:
+		p.genZshDynamicCompletion(b)
+	case shell == "zsh":
+		p.genZshCompletion(b)
+	case shell == "fish" && dynamic:
+		p.genFishDynamicCompletion(b)
+	case shell == "fish":
+		p.genFishCompletion(b)
+	default:
+		return &ErrUnsupportedShell{shell}
+	}
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// progName returns the name of the program, which is the name of the root
+// parser or the base name of the executable if the root parser is unnamed.
+func (p *parser) progName() string {
+	r := p
+	for r.parent != nil {
+		r = r.parent
+	}
+	if r.name != "" {
+		return r.name
+	}
+	return filepath.Base(os.Args[0])
+}
+
+// cmdPath returns the names of the parser's command and the commands of its
+// ancestors, excluding the root parser.
+func (p *parser) cmdPath() []string {
+	path := []string{}
+	for c := p; c.parent != nil; c = c.parent {
+		path = append([]string{c.name}, path...)
+	}
+	return path
+}
+
+// walk invokes the function for the parser and each of its commands' parsers,
+// depth first.
+func (p *parser) walk(f func(c *parser)) {
+	f(p)
+	for _, c := range p.commands {
+		c.walk(f)
+	}
+}
+
+var shellFuncNameRx = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// completionFuncName returns the name of the shell function that completes
+// the parser's options and commands, ex. _prog_remote_add.
+func (p *parser) completionFuncName() string {
+	parts := append([]string{p.progName()}, p.cmdPath()...)
+	return "_" + shellFuncNameRx.ReplaceAllString(strings.Join(parts, "_"), "_")
+}
+
+// argName returns the option's argument text without the brackets that
+// enclose an optional argument.
+func (o *optDef) argName() string {
+	return strings.TrimSuffix(strings.TrimPrefix(o.argText, "["), "]")
+}
+
+func (p *parser) genBashCompletion(b *bytes.Buffer) {
+	prog := p.progName()
+	fn := p.completionFuncName()
+
+	fmt.Fprintf(b, "# bash completion for %s\n\n", prog)
+	fmt.Fprintf(b, "%s() {\n", fn)
+	b.WriteString(`    local line="${COMP_LINE:0:COMP_POINT}" cur="" cmd="" skip="" w
+    local opts="" cmds="" choices="" name=""
+    local -a words
+    read -ra words <<< "$line"
+    if [[ "$line" != *[[:space:]] ]]; then
+        cur="${words[${#words[@]}-1]}"
+        unset 'words[${#words[@]}-1]'
+    fi
+
+    for w in "${words[@]:1}"; do
+        if [[ -n "$skip" ]]; then
+            skip=""
+            continue
+        fi
+        case "$cmd:$w" in
+`)
+
+	// the options that take their arguments from the next word are skipped
+	// when finding the command to complete
+	p.walk(func(c *parser) {
+		path := strings.Join(c.cmdPath(), " ")
+		for _, sub := range c.commands {
+			fmt.Fprintf(b, "        %q)\n            cmd=%q ;;\n",
+				path+":"+sub.name, strings.Join(sub.cmdPath(), " "))
+		}
+		words := []string{}
+		for _, o := range c.visibleOpts() {
+			if o.optType != RequiredArgument {
+				continue
+			}
+			if o.opt > 0 {
+				words = append(words, fmt.Sprintf("%q", fmt.Sprintf(
+					"%s:-%c", path, o.opt)))
+			}
+			for _, name := range o.longNames() {
+				words = append(words, fmt.Sprintf("%q", fmt.Sprintf(
+					"%s:--%s", path, name)))
+			}
+		}
+		if len(words) > 0 {
+			fmt.Fprintf(b, "        %s)\n            skip=1 ;;\n",
+				strings.Join(words, "|"))
+		}
+	})
+
+	b.WriteString(`        esac
+    done
+
+    if [[ "$cur" == --*=* ]]; then
+        name="${cur%%=*}"
+    else
+        name="${words[${#words[@]}-1]}"
+    fi
+
+    case "$cmd" in
+`)
+
+	p.walk(func(c *parser) {
+		fmt.Fprintf(b, "    %q)\n", strings.Join(c.cmdPath(), " "))
+
+		opts := []string{}
+		choices := []string{}
+		for _, o := range c.visibleOpts() {
+			names := []string{}
+			if o.opt > 0 {
+				opts = append(opts, fmt.Sprintf("-%c", o.opt))
+				if o.optType == RequiredArgument {
+					names = append(names, fmt.Sprintf("%q", fmt.Sprintf(
+						"-%c", o.opt)))
+				}
+			}
+			for _, name := range o.longNames() {
+				switch o.optType {
+				case NoArgument:
+					opts = append(opts, "--"+name)
+					if o.negatable {
+						opts = append(opts, "--no-"+name)
+					}
+				case OptionalArgument:
+					opts = append(opts, "--"+name, "--"+name+"=")
+				case RequiredArgument:
+					opts = append(opts, "--"+name+"=")
+				}
+				if o.optType != NoArgument {
+					names = append(names, fmt.Sprintf("%q", "--"+name))
+				}
+			}
+			if len(o.choices) > 0 && len(names) > 0 {
+				choices = append(choices, fmt.Sprintf(
+					"        %s)\n            choices=%q ;;\n",
+					strings.Join(names, "|"), strings.Join(o.choices, " ")))
+			}
+		}
+
+		fmt.Fprintf(b, "        opts=%q\n", strings.Join(opts, " "))
+		if len(c.commands) > 0 {
+			cmds := []string{}
+			for _, sub := range c.commands {
+				cmds = append(cmds, sub.name)
+			}
+			fmt.Fprintf(b, "        cmds=%q\n", strings.Join(cmds, " "))
+		}
+		if len(choices) > 0 {
+			b.WriteString("        case \"$name\" in\n")
+			for _, s := range choices {
+				b.WriteString(s)
+			}
+			b.WriteString("        esac\n")
+		}
+		b.WriteString("        ;;\n")
+	})
+
+	b.WriteString(`    esac
+
+    if [[ "$cur" == --*=* ]]; then
+        COMPREPLY=($(compgen -W "$choices" -- "${cur#*=}"))
+        if [[ "$COMP_WORDBREAKS" != *=* ]]; then
+            COMPREPLY=("${COMPREPLY[@]/#/$name=}")
+        fi
+        return
+    fi
+
+    if [[ -n "$choices" ]]; then
+        COMPREPLY=($(compgen -W "$choices" -- "$cur"))
+        return
+    fi
+
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W "$opts" -- "$cur"))
+        if [[ ${#COMPREPLY[@]} -eq 1 && "${COMPREPLY[0]}" == *= ]]; then
+            compopt -o nospace
+        fi
+        return
+    fi
+
+    if [[ -n "$cmds" ]]; then
+        COMPREPLY=($(compgen -W "$cmds" -- "$cur"))
+    fi
+}
+`)
+	fmt.Fprintf(b, "\ncomplete -o default -F %s %s\n", fn, prog)
+}
+
+// zshEscape escapes the string for use inside of a single-quoted _arguments
+// spec in a zsh completion script.
+func zshEscape(s string) string {
+	return strings.NewReplacer(
+		`'`, `'\''`, `[`, `\[`, `]`, `\]`, `:`, `\:`).Replace(s)
+}
+
+func (p *parser) genZshCompletion(b *bytes.Buffer) {
+	fmt.Fprintf(b, "#compdef %s\n", p.progName())
+
+	p.walk(func(c *parser) {
+		fmt.Fprintf(b, "\n%s() {\n", c.completionFuncName())
+		b.WriteString("    local context state state_descr line\n")
+		b.WriteString("    typeset -A opt_args\n\n")
+		b.WriteString("    _arguments -C -s")
+
+		for _, o := range c.visibleOpts() {
+			forms := []string{}
+			if o.opt > 0 {
+				switch {
+				case o.counter:
+					// the option character of a counter never takes an
+					// argument, so it has a spec of its own
+					fmt.Fprintf(b, " \\\n        '*'-%c'[%s]'",
+						o.opt, zshEscape(o.desc))
+				case o.optType == NoArgument:
+					forms = append(forms, fmt.Sprintf("-%c", o.opt))
+				case o.optType == RequiredArgument:
+					forms = append(forms, fmt.Sprintf("-%c+", o.opt))
+				case o.optType == OptionalArgument:
+					forms = append(forms, fmt.Sprintf("-%c-", o.opt))
+				}
+			}
+			for _, name := range o.longNames() {
+				switch o.optType {
+				case NoArgument:
+					forms = append(forms, "--"+name)
+					if o.negatable {
+						forms = append(forms, "--no-"+name)
+					}
+				case RequiredArgument:
+					forms = append(forms, "--"+name+"=")
+				case OptionalArgument:
+					forms = append(forms, "--"+name+"=-")
+				}
+			}
+
+			if len(forms) == 0 {
+				continue
+			}
+
+			spec := "'*'" + forms[0]
+			if len(forms) > 1 {
+				spec = "'*'{" + strings.Join(forms, ",") + "}"
+			}
+			spec += "'[" + zshEscape(o.desc) + "]"
+
+			if o.optType != NoArgument {
+				action := ""
+				if len(o.choices) > 0 {
+					action = "(" + strings.Join(o.choices, " ") + ")"
+				}
+				colon := ":"
+				if o.optType == OptionalArgument {
+					colon = "::"
+				}
+				spec += colon + zshEscape(o.argName()) + ":" + action
+			}
+			spec += "'"
+
+			fmt.Fprintf(b, " \\\n        %s", spec)
+		}
+
+		if len(c.commands) == 0 {
+			b.WriteString(" \\\n        '*:file:_files'\n")
+			b.WriteString("}\n")
+			return
+		}
+
+		b.WriteString(" \\\n        '1: :->cmds' \\\n        '*:: :->args'\n\n")
+		b.WriteString("    case $state in\n")
+		b.WriteString("    cmds)\n")
+		b.WriteString("        local -a cmds\n")
+		b.WriteString("        cmds=(\n")
+		for _, sub := range c.commands {
+			fmt.Fprintf(b, "            '%s:%s'\n", zshEscape(sub.name),
+				strings.Replace(sub.desc, "'", `'\''`, -1))
+		}
+		b.WriteString("        )\n")
+		b.WriteString("        _describe 'command' cmds\n")
+		b.WriteString("        ;;\n")
+		b.WriteString("    args)\n")
+		b.WriteString("        case $line[1] in\n")
+		for _, sub := range c.commands {
+			fmt.Fprintf(b, "        %s)\n            %s ;;\n",
+				sub.name, sub.completionFuncName())
+		}
+		b.WriteString("        esac\n")
+		b.WriteString("        ;;\n")
+		b.WriteString("    esac\n")
+		b.WriteString("}\n")
+	})
+
+	fmt.Fprintf(b, "\n%s \"$@\"\n", p.completionFuncName())
+}
+
+// fishQuote quotes the string for use in a fish completion script.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+func (p *parser) genFishCompletion(b *bytes.Buffer) {
+	prog := p.progName()
+	fn := strings.TrimPrefix(p.completionFuncName(), "_")
+
+	fmt.Fprintf(b, "# fish completion for %s\n\n", prog)
+
+	// the function tests whether the command being completed is the one
+	// named by the function's argument
+	fmt.Fprintf(b, "function __%s_cmd\n", fn)
+	b.WriteString("    set -l cmd ''\n")
+	b.WriteString("    for w in (commandline -opc)[2..-1]\n")
+	b.WriteString("        switch \"$cmd:$w\"\n")
+	p.walk(func(c *parser) {
+		path := strings.Join(c.cmdPath(), " ")
+		for _, sub := range c.commands {
+			fmt.Fprintf(b, "            case %s\n                set cmd %s\n",
+				fishQuote(path+":"+sub.name),
+				fishQuote(strings.Join(sub.cmdPath(), " ")))
+		}
+	})
+	b.WriteString("        end\n")
+	b.WriteString("    end\n")
+	b.WriteString("    test \"$cmd\" = \"$argv[1]\"\n")
+	b.WriteString("end\n")
+
+	p.walk(func(c *parser) {
+		b.WriteByte('\n')
+		cond := fmt.Sprintf("-n %s", fishQuote(fmt.Sprintf(
+			"__%s_cmd %s", fn, fishQuote(strings.Join(c.cmdPath(), " ")))))
+
+		for _, o := range c.visibleOpts() {
+			fmt.Fprintf(b, "complete -c %s %s", prog, cond)
+			if o.opt > 0 {
+				fmt.Fprintf(b, " -s %c", o.opt)
+			}
+			for _, name := range o.longNames() {
+				fmt.Fprintf(b, " -l %s", name)
+				if o.negatable {
+					fmt.Fprintf(b, " -l no-%s", name)
+				}
+			}
+			if o.optType == RequiredArgument {
+				b.WriteString(" -r")
+			}
+			if len(o.choices) > 0 {
+				fmt.Fprintf(b, " -f -a %s",
+					fishQuote(strings.Join(o.choices, " ")))
+			}
+			if o.desc != "" {
+				fmt.Fprintf(b, " -d %s", fishQuote(o.desc))
+			}
+			b.WriteByte('\n')
+		}
+
+		for _, sub := range c.commands {
+			fmt.Fprintf(b, "complete -c %s %s -f -a %s",
+				prog, cond, fishQuote(sub.name))
+			if sub.desc != "" {
+				fmt.Fprintf(b, " -d %s", fishQuote(sub.desc))
+			}
+			b.WriteByte('\n')
+		}
+	})
+}
+
+func (p *parser) genBashDynamicCompletion(b *bytes.Buffer) {
+	prog := p.progName()
+	fn := p.completionFuncName()
+
+	fmt.Fprintf(b, "# bash completion for %s\n\n", prog)
+	fmt.Fprintf(b, "%s() {\n", fn)
+	b.WriteString(`    local line="${COMP_LINE:0:COMP_POINT}" cur="" c
+    local -a words
+    read -ra words <<< "$line"
+    if [[ "$line" != *[[:space:]] ]]; then
+        cur="${words[${#words[@]}-1]}"
+        unset 'words[${#words[@]}-1]'
+    fi
+
+    COMPREPLY=()
+    while IFS= read -r c; do
+        if [[ "$cur" == *=* && "$COMP_WORDBREAKS" == *=* ]]; then
+            c="${c#"${cur%=*}="}"
+        fi
+        COMPREPLY+=("$c")
+    done < <("${words[0]}" `)
+	b.WriteString(CompleteCommand)
+	b.WriteString(` "${words[@]:1}" "$cur" 2>/dev/null)
+
+    if [[ ${#COMPREPLY[@]} -eq 1 && "${COMPREPLY[0]}" == *= ]]; then
+        compopt -o nospace
+    fi
+}
+`)
+	fmt.Fprintf(b, "\ncomplete -o default -F %s %s\n", fn, prog)
+}
+
+func (p *parser) genZshDynamicCompletion(b *bytes.Buffer) {
+	fmt.Fprintf(b, "#compdef %s\n", p.progName())
+	fmt.Fprintf(b, "\n%s() {\n", p.completionFuncName())
+	b.WriteString("    local -a candidates\n")
+	fmt.Fprintf(b, "    candidates=(${(f)\"$(\"${words[1]}\" %s "+
+		"\"${(@)words[2,CURRENT]}\" 2>/dev/null)\"})\n", CompleteCommand)
+	b.WriteString(`    if (( ! ${#candidates} )); then
+        _files
+        return
+    fi
+    compadd -S '' -- ${(M)candidates:#*=}
+    compadd -- ${candidates:#*=}
+}
+`)
+	fmt.Fprintf(b, "\n%s \"$@\"\n", p.completionFuncName())
+}
+
+func (p *parser) genFishDynamicCompletion(b *bytes.Buffer) {
+	prog := p.progName()
+	fn := strings.TrimPrefix(p.completionFuncName(), "_")
+
+	fmt.Fprintf(b, "# fish completion for %s\n\n", prog)
+	fmt.Fprintf(b, "function __%s_complete\n", fn)
+	b.WriteString("    set -l words (commandline -opc)\n")
+	fmt.Fprintf(b, "    $words[1] %s $words[2..-1] (commandline -ct)\n",
+		CompleteCommand)
+	b.WriteString("end\n\n")
+	fmt.Fprintf(b, "complete -c %s -f -a %s\n",
+		prog, fishQuote(fmt.Sprintf("(__%s_complete)", fn)))
+}
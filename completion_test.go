@@ -0,0 +1,138 @@
+package gotopt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateCompletionBash(t *testing.T) {
+	p := newParser()
+	p.name = "prog"
+	p.Opt('v', "verbose", NoArgument, "", "Be verbose", Persistent())
+	p.Opt('c', "color", RequiredArgument, "when", "When to use [color]",
+		Choices("auto", "always", "never"))
+	p.Opt(0, "xist", OptionalArgument, "val", "It's a value")
+	remote := p.Command("remote", "Manage remotes")
+	remote.Command("add", "Add a remote").
+		Opt('f', "fetch", NoArgument, "", "Fetch the remote")
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, p.GenerateCompletion("bash", b))
+	s := b.String()
+	assert.Contains(t, s, "_prog() {\n")
+	assert.Contains(t, s, `        ":remote")
+            cmd="remote" ;;
+        ":-c"|":--color")
+            skip=1 ;;
+        "remote:add")
+            cmd="remote add" ;;
+`)
+	assert.Contains(t, s, `    "")
+        opts="-v --verbose -c --color= --xist --xist="
+        cmds="remote"
+        case "$name" in
+        "-c"|"--color")
+            choices="auto always never" ;;
+        esac
+        ;;
+`)
+	assert.Contains(t, s, `    "remote add")
+        opts="-f --fetch -v --verbose"
+        ;;
+`)
+	assert.Contains(t, s, "complete -o default -F _prog prog\n")
+}
+
+func TestGenerateCompletionZsh(t *testing.T) {
+	p := newParser()
+	p.name = "prog"
+	p.Opt('v', "verbose", NoArgument, "", "Be verbose", Persistent())
+	p.Opt('c', "color", RequiredArgument, "when", "When to use [color]",
+		Choices("auto", "always", "never"))
+	p.Opt(0, "xist", OptionalArgument, "val", "It's a value")
+	remote := p.Command("remote", "Manage remotes")
+	remote.Command("add", "Add a remote").
+		Opt('f', "fetch", NoArgument, "", "Fetch the remote")
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, p.GenerateCompletion("zsh", b))
+	s := b.String()
+	assert.Contains(t, s, "#compdef prog\n")
+	assert.Contains(t, s, `'*'{-v,--verbose}'[Be verbose]'`)
+	assert.Contains(t, s,
+		`'*'{-c+,--color=}'[When to use \[color\]]:when:(auto always never)'`)
+	assert.Contains(t, s, `'*'--xist=-'[It'\''s a value]::val:'`)
+	assert.Contains(t, s, `'remote:Manage remotes'`)
+	assert.Contains(t, s, "        remote)\n            _prog_remote ;;\n")
+	assert.Contains(t, s, "\n_prog_remote_add() {\n")
+}
+
+func TestGenerateCompletionZshCounter(t *testing.T) {
+	p := newParser()
+	p.name = "prog"
+	p.Opt('v', "verbose", NoArgument, "", "Be verbose", Counter())
+	p.Opt('q', "", NoArgument, "", "Be quiet", Counter())
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, p.GenerateCompletion("zsh", b))
+	s := b.String()
+	assert.Contains(t, s, " \\\n        '*'-v'[Be verbose]' \\\n"+
+		"        '*'--verbose=-'[Be verbose]::n:' \\\n"+
+		"        '*'-q'[Be quiet]' \\\n")
+}
+
+func TestGenerateCompletionAlias(t *testing.T) {
+	p := newParser()
+	p.name = "prog"
+	p.Opt('c', "color", RequiredArgument, "when", "When to use color",
+		Alias("colour"), Choices("auto", "never"))
+	p.Opt(0, "pager", NoArgument, "", "Use a pager",
+		Alias("less"), Negatable())
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, p.GenerateCompletion("bash", b))
+	s := b.String()
+	assert.Contains(t, s, `":-c"|":--color"|":--colour")`)
+	assert.Contains(t, s, `opts="-c --color= --colour= `+
+		`--pager --no-pager --less --no-less"`)
+	assert.Contains(t, s, `"-c"|"--color"|"--colour")`)
+
+	b.Reset()
+	assert.NoError(t, p.GenerateCompletion("zsh", b))
+	s = b.String()
+	assert.Contains(t, s, `'*'{-c+,--color=,--colour=}`)
+	assert.Contains(t, s, `'*'{--pager,--no-pager,--less,--no-less}`)
+
+	b.Reset()
+	assert.NoError(t, p.GenerateCompletion("fish", b))
+	s = b.String()
+	assert.Contains(t, s, "-s c -l color -l colour -r")
+	assert.Contains(t, s, "-l pager -l no-pager -l less -l no-less")
+}
+
+func TestGenerateCompletionFish(t *testing.T) {
+	p := newParser()
+	p.name = "prog"
+	p.Opt('c', "color", RequiredArgument, "when", "When to use [color]",
+		Choices("auto", "always", "never"))
+	p.Opt(0, "xist", OptionalArgument, "val", "It's a value")
+	p.Command("remote", "Manage remotes").Command("add", "Add a remote")
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, p.GenerateCompletion("fish", b))
+	s := b.String()
+	assert.Contains(t, s, "function __prog_cmd\n")
+	assert.Contains(t, s, "complete -c prog -n '__prog_cmd \\'\\'' "+
+		"-s c -l color -r -f -a 'auto always never' "+
+		"-d 'When to use [color]'\n")
+	assert.Contains(t, s, "complete -c prog -n '__prog_cmd \\'remote\\'' "+
+		"-f -a 'add' -d 'Add a remote'\n")
+	assert.Contains(t, s, "-l xist -d 'It\\'s a value'\n")
+}
+
+func TestGenerateCompletionUnsupported(t *testing.T) {
+	err := NewParser().GenerateCompletion("csh", &bytes.Buffer{})
+	assert.Equal(t, &ErrUnsupportedShell{"csh"}, err)
+}
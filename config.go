@@ -0,0 +1,336 @@
+package gotopt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConfigFormats are IniConfig and JSONConfig
+type ConfigFormats int
+
+const (
+	// IniConfig is for INI-style files as well as simple key=value rc files.
+	//
+	// Each line is an option's long name, optionally followed by an equals
+	// sign and the option's argument. The argument may be enclosed in single
+	// or double quotes. Blank lines and lines that begin with '#' or ';' are
+	// ignored. The line "[section]" prefixes the long names on the lines that
+	// follow it with "section-". A negatable option is negated by its long
+	// name preceded by "no-". For example:
+	//
+	//     # ~/.toolrc
+	//     verbose
+	//     time = 37
+	//
+	//     [server]
+	//     port = 8080
+	//
+	// The above file sets the options --verbose, --time=37, and
+	// --server-port=8080.
+	IniConfig ConfigFormats = iota
+
+	// JSONConfig is for JSON files.
+	//
+	// The file is an object whose keys are long names. A value may be a
+	// string, number, bool, null, or an array of those. The value true sets
+	// an option that does not take an argument, and the values false and
+	// null are ignored for such options, except that false negates a
+	// negatable option. An array sets the option once for
+	// each of its elements. A nested object prefixes the keys inside of it
+	// with its key and a dash. For example:
+	//
+	//     {
+	//         "verbose": true,
+	//         "time": 37,
+	//         "server": { "port": 8080 }
+	//     }
+	//
+	// The above file sets the options --verbose, --time=37, and
+	// --server-port=8080.
+	JSONConfig
+)
+
+// configValue is the value of an option as read from a configuration file.
+type configValue struct {
+	o       *optDef
+	value   string
+	file    string
+	line    int
+	negated bool
+}
+
+// LoadConfig reads option values from a configuration file.
+func (p *parser) LoadConfig(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return &ErrConfigFile{File: path, Err: err}
+	}
+	defer f.Close()
+
+	format := IniConfig
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = JSONConfig
+	}
+	return p.LoadConfigFrom(f, path, format)
+}
+
+// LoadConfigFrom reads option values from the provided reader.
+func (p *parser) LoadConfigFrom(
+	r io.Reader, name string, format ConfigFormats) error {
+
+	var (
+		vals []*configValue
+		err  error
+	)
+	switch format {
+	case JSONConfig:
+		vals, err = p.readJSONConfig(r, name)
+	default:
+		vals, err = p.readIniConfig(r, name)
+	}
+	if err != nil {
+		return err
+	}
+	p.config = append(p.config, vals...)
+	return nil
+}
+
+// newConfigValue validates the value read from a configuration file for the
+// option with the given long name. A nil value indicates that the option was
+// given without an argument.
+func (p *parser) newConfigValue(
+	longName string, value *string, file string, line int) (*configValue, error) {
+
+	negated := false
+	o, ok := p.longOpts[longName]
+	if !ok && strings.HasPrefix(longName, "no-") {
+		o, ok = p.longOpts[longName[3:]]
+		ok = ok && o.negatable
+		negated = true
+	}
+	if !ok {
+		return nil, &ErrConfigFile{file, line, &ErrUnknownOpt{0, longName}}
+	}
+
+	if value == nil {
+		if o.optType == RequiredArgument {
+			return nil, &ErrConfigFile{file, line, fmt.Errorf(
+				"option '--%s' requires an argument", longName)}
+		}
+		return &configValue{o, "", file, line, negated}, nil
+	}
+
+	if o.optType == NoArgument {
+		return nil, &ErrConfigFile{file, line, fmt.Errorf(
+			"option '--%s' doesn't allow an argument", longName)}
+	}
+	return &configValue{o, *value, file, line, false}, nil
+}
+
+func (p *parser) readIniConfig(
+	r io.Reader, name string) ([]*configValue, error) {
+
+	var (
+		vals    []*configValue
+		prefix  string
+		lineNum int
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' {
+			if line[len(line)-1] != ']' {
+				return nil, &ErrConfigFile{name, lineNum, fmt.Errorf(
+					"invalid section '%s'", line)}
+			}
+			prefix = strings.TrimSpace(line[1 : len(line)-1])
+			if prefix != "" {
+				prefix += "-"
+			}
+			continue
+		}
+
+		var value *string
+		key := line
+		if i := strings.IndexByte(line, '='); i > -1 {
+			key = strings.TrimSpace(line[:i])
+			v := unquote(strings.TrimSpace(line[i+1:]))
+			value = &v
+		}
+
+		cv, err := p.newConfigValue(prefix+key, value, name, lineNum)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, cv)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, &ErrConfigFile{File: name, Err: err}
+	}
+
+	return vals, nil
+}
+
+// unquote removes matching single or double quotes that enclose a string.
+func unquote(s string) string {
+	if len(s) > 1 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func (p *parser) readJSONConfig(
+	r io.Reader, name string) ([]*configValue, error) {
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, &ErrConfigFile{File: name, Err: err}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	// lineAt returns the line number at the given offset
+	lineAt := func(offset int64) int {
+		return 1 + bytes.Count(data[:offset], []byte{'\n'})
+	}
+
+	fail := func(err error) error {
+		line := lineAt(dec.InputOffset())
+		if serr, ok := err.(*json.SyntaxError); ok {
+			line = lineAt(serr.Offset)
+		}
+		return &ErrConfigFile{name, line, err}
+	}
+
+	var (
+		vals       []*configValue
+		readObject func(prefix string) error
+	)
+
+	// readValue reads a scalar value for the key. A nil value is returned
+	// for values that do not set the option.
+	readValue := func(key string, tok json.Token, line int) error {
+		var value *string
+		switch tv := tok.(type) {
+		case nil:
+			return nil
+		case string:
+			value = &tv
+		case json.Number:
+			s := tv.String()
+			value = &s
+		case bool:
+			if o, ok := p.longOpts[key]; ok && o.optType == NoArgument {
+				if !tv && o.negatable {
+					key = "no-" + key
+				} else if !tv {
+					return nil
+				}
+			} else {
+				s := fmt.Sprintf("%v", tv)
+				value = &s
+			}
+		default:
+			return &ErrConfigFile{name, line, fmt.Errorf(
+				"invalid value for option '--%s'", key)}
+		}
+		cv, err := p.newConfigValue(key, value, name, line)
+		if err != nil {
+			return err
+		}
+		vals = append(vals, cv)
+		return nil
+	}
+
+	readObject = func(prefix string) error {
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return fail(err)
+			}
+			key := prefix + tok.(string)
+			line := lineAt(dec.InputOffset())
+
+			if tok, err = dec.Token(); err != nil {
+				return fail(err)
+			}
+
+			switch tok {
+			case json.Delim('{'):
+				if err := readObject(key + "-"); err != nil {
+					return err
+				}
+			case json.Delim('['):
+				for dec.More() {
+					if tok, err = dec.Token(); err != nil {
+						return fail(err)
+					}
+					if err := readValue(key, tok, line); err != nil {
+						return err
+					}
+				}
+			default:
+				if err := readValue(key, tok, line); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// consume the closing delimiter of the object or array
+			if _, err := dec.Token(); err != nil {
+				return fail(err)
+			}
+		}
+		return nil
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fail(err)
+	}
+	if tok != json.Delim('{') {
+		return nil, &ErrConfigFile{name, 1, fmt.Errorf("expected an object")}
+	}
+	if err := readObject(""); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, fail(err)
+	}
+
+	return vals, nil
+}
+
+// parseConfig sends the options read from configuration files that were not
+// parsed from the argument list or read from the environment.
+func (p *parser) parseConfig(l *stateList) {
+	parsed := map[*optDef]bool{}
+	for _, cv := range p.config {
+		if _, ok := l.optIndices[cv.o]; ok {
+			parsed[cv.o] = true
+		}
+	}
+	for _, cv := range p.config {
+		if parsed[cv.o] || !cv.o.enabled() {
+			continue
+		}
+		l.sendOpt(newParsedOptState(
+			cv.o, cv.value, cv.negated, FromConfig, l.optIndices))
+	}
+}
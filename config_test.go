@@ -0,0 +1,174 @@
+package gotopt
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParserConfigIni(t *testing.T) {
+	var (
+		tm   string
+		port int
+	)
+	p := NewParser()
+	p.Opt('n', "name", NoArgument, "", "")
+	p.StringVar(&tm, 't', "time", "", "")
+	p.IntVar(&port, 0, "server-port", "", "")
+
+	assert.NoError(t, p.LoadConfigFrom(strings.NewReader(`
+# comment
+; comment
+name
+time = "37"
+
+[server]
+port=8080
+`), "tpconfig01.ini", IniConfig))
+
+	ps, err := p.ParseAll([]string{"tpconfig01", "effie"})
+	assert.NoError(t, err)
+	ps = ps.First()
+
+	o := ps.Value().(Option)
+	assert.EqualValues(t, 'n', o.Opt())
+	assert.Equal(t, FromConfig, o.Source())
+
+	ps, _ = ps.Next()
+	o = ps.Value().(Option)
+	assert.EqualValues(t, 't', o.Opt())
+	assert.Equal(t, "37", o.Value())
+
+	ps, _ = ps.Next()
+	o = ps.Value().(Option)
+	assert.Equal(t, "server-port", o.LongName())
+	assert.Equal(t, "8080", o.Value())
+
+	ps, _ = ps.Next()
+	assert.Equal(t, []string{"effie"}, ps.Value())
+
+	assert.Equal(t, "37", tm)
+	assert.Equal(t, 8080, port)
+}
+
+func TestParserConfigJSON(t *testing.T) {
+	l := &testListValue{}
+	var port int
+	p := NewParser()
+	p.Opt('n', "name", NoArgument, "", "")
+	p.Opt('q', "quiet", NoArgument, "", "")
+	p.Var(l, 'x', "xist", RequiredArgument, "", "")
+	p.IntVar(&port, 0, "server-port", "", "")
+
+	assert.NoError(t, p.LoadConfigFrom(strings.NewReader(`{
+	"name": true,
+	"quiet": false,
+	"xist": ["a", "b"],
+	"server": { "port": 8080 }
+}`), "tpconfig02.json", JSONConfig))
+
+	ps, err := p.ParseAll([]string{"tpconfig02"})
+	assert.NoError(t, err)
+	ps = ps.First()
+
+	o := ps.Value().(Option)
+	assert.EqualValues(t, 'n', o.Opt())
+	assert.Equal(t, FromConfig, o.Source())
+
+	ps, _ = ps.Next()
+	assert.Equal(t, "a", ps.Value().(Option).Value())
+	ps, _ = ps.Next()
+	assert.Equal(t, "b", ps.Value().(Option).Value())
+	ps, _ = ps.Next()
+	assert.Equal(t, "8080", ps.Value().(Option).Value())
+
+	assert.Equal(t, "a,b", l.String())
+	assert.Equal(t, 8080, port)
+}
+
+func TestParserConfigPrecedence(t *testing.T) {
+	defer setTestEnv("GOTOPT_TEST_TIME", "env")()
+
+	p := NewParser()
+	p.Opt('t', "time", RequiredArgument, "", "", Env("GOTOPT_TEST_TIME"))
+	p.Opt('x', "xist", RequiredArgument, "", "")
+	p.Opt('y', "yes", RequiredArgument, "", "")
+
+	assert.NoError(t, p.LoadConfigFrom(strings.NewReader(
+		"time=cfg\nxist=cfg\nyes=cfg\n"), "tpconfig03", IniConfig))
+
+	ps, err := p.ParseAll([]string{"tpconfig03", "-xargv"})
+	assert.NoError(t, err)
+
+	vals := map[string]Option{}
+	for ps = ps.First(); ps != nil; ps, _ = ps.Next() {
+		if o, ok := ps.Value().(Option); ok {
+			vals[o.LongName()] = o
+		}
+	}
+	assert.Equal(t, "argv", vals["xist"].Value())
+	assert.Equal(t, FromArgv, vals["xist"].Source())
+	assert.Equal(t, "env", vals["time"].Value())
+	assert.Equal(t, FromEnv, vals["time"].Source())
+	assert.Equal(t, "cfg", vals["yes"].Value())
+	assert.Equal(t, FromConfig, vals["yes"].Source())
+}
+
+func TestParserConfigErrors(t *testing.T) {
+	p := NewParser()
+	p.Opt('n', "name", NoArgument, "", "")
+	p.Opt('t', "time", RequiredArgument, "", "")
+
+	load := func(s string, format ConfigFormats) error {
+		return p.LoadConfigFrom(strings.NewReader(s), "tpconfig04", format)
+	}
+
+	err := load("name\n\nnope=1\n", IniConfig)
+	assert.IsType(t, &ErrConfigFile{}, err)
+	assert.Equal(t, 3, err.(*ErrConfigFile).Line)
+	assert.IsType(t, &ErrUnknownOpt{}, err.(*ErrConfigFile).Err)
+	assert.EqualError(t, err, "tpconfig04:3: unknown option '--nope'")
+
+	assert.EqualError(t, load("name=true", IniConfig),
+		"tpconfig04:1: option '--name' doesn't allow an argument")
+	assert.EqualError(t, load("\ntime", IniConfig),
+		"tpconfig04:2: option '--time' requires an argument")
+	assert.EqualError(t, load("[name", IniConfig),
+		"tpconfig04:1: invalid section '[name'")
+
+	assert.EqualError(t, load("{\n\"name\": true,\n\"nope\": 1\n}", JSONConfig),
+		"tpconfig04:3: unknown option '--nope'")
+	assert.EqualError(t, load("{\n\"name\": \"yes\"\n}", JSONConfig),
+		"tpconfig04:2: option '--name' doesn't allow an argument")
+
+	err = load("{\n\"time\": 37,\n\"name\" true\n}", JSONConfig)
+	assert.IsType(t, &ErrConfigFile{}, err)
+	assert.Equal(t, 3, err.(*ErrConfigFile).Line)
+
+	assert.Empty(t, p.(*parser).config)
+}
+
+func TestParserLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gotopt")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"time": "37s"}`), 0644))
+
+	p := NewParser()
+	p.Opt('t', "time", RequiredArgument, "", "")
+	assert.NoError(t, p.LoadConfig(path))
+
+	ps, err := p.ParseAll([]string{"tpconfig05"})
+	assert.NoError(t, err)
+	assert.Equal(t, "37s", ps.First().Value().(Option).Value())
+
+	err = p.LoadConfig(filepath.Join(dir, "nope.ini"))
+	assert.IsType(t, &ErrConfigFile{}, err)
+	assert.Equal(t, 0, err.(*ErrConfigFile).Line)
+}
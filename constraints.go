@@ -0,0 +1,258 @@
+package gotopt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Constraints are MutuallyExclusive, AtLeastOne, ExactlyOne, Requires, and
+// ConflictsWith
+type Constraints int
+
+const (
+	// MutuallyExclusive is for options of which no more than one may be
+	// given
+	MutuallyExclusive Constraints = iota
+
+	// AtLeastOne is for options of which at least one must be given
+	AtLeastOne
+
+	// ExactlyOne is for options of which exactly one must be given
+	ExactlyOne
+
+	// Requires is for an option that may be given only if all of the other
+	// options are given as well
+	Requires
+
+	// ConflictsWith is for an option that may not be given if any of the
+	// other options are given
+	ConflictsWith
+)
+
+// constraint is a constraint on the options given to a parser. The first
+// option of a Requires or ConflictsWith constraint is the option that
+// requires or conflicts with the others.
+type constraint struct {
+	kind Constraints
+	opts []*optDef
+}
+
+// MutuallyExclusive declares that no more than one of the options may be
+// given.
+func (p *parser) MutuallyExclusive(names ...string) {
+	p.constrain(MutuallyExclusive, names...)
+}
+
+// AtLeastOne declares that at least one of the options must be given.
+func (p *parser) AtLeastOne(names ...string) {
+	p.constrain(AtLeastOne, names...)
+}
+
+// ExactlyOne declares that exactly one of the options must be given.
+func (p *parser) ExactlyOne(names ...string) {
+	p.constrain(ExactlyOne, names...)
+}
+
+// Requires declares that if the option is given, the other options must be
+// given as well.
+func (p *parser) Requires(name string, names ...string) {
+	p.constrain(Requires, append([]string{name}, names...)...)
+}
+
+// ConflictsWith declares that if the option is given, none of the other
+// options may be given.
+func (p *parser) ConflictsWith(name string, names ...string) {
+	p.constrain(ConflictsWith, append([]string{name}, names...)...)
+}
+
+func (p *parser) constrain(kind Constraints, names ...string) {
+	if len(names) < 2 {
+		panic("constraint requires at least two options")
+	}
+	c := &constraint{kind: kind}
+	for _, name := range names {
+		o := p.lookupOptDef(name)
+		if o == nil {
+			panic("unknown option " + name)
+		}
+		c.opts = append(c.opts, o)
+	}
+	p.constraints = append(p.constraints, c)
+}
+
+// lookupOptDef returns the option the parser accepts with the given name.
+// The name may be a long name, an alias, or an option character, with or
+// without the leading dashes, ex. "file", "--file", "f", or "-f".
+func (p *parser) lookupOptDef(name string) *optDef {
+	opts := p.allOpts()
+	switch {
+	case strings.HasPrefix(name, "--"):
+		name = name[2:]
+	case strings.HasPrefix(name, "-") && utf8.RuneCountInString(name) == 2:
+		name = name[1:]
+	}
+	for _, o := range opts {
+		for _, n := range o.longNames() {
+			if n == name {
+				return o
+			}
+		}
+	}
+	if r, size := utf8.DecodeRuneInString(name); size > 0 && size == len(name) {
+		for _, o := range opts {
+			if o.opt == int(r) {
+				return o
+			}
+		}
+	}
+	return nil
+}
+
+// optName returns the name of the option as shown in errors and the usage
+// synopsis, ex. "--file" or "-f".
+func optName(o *optDef) string {
+	if o.longName != "" {
+		return "--" + o.longName
+	}
+	return fmt.Sprintf("-%c", o.opt)
+}
+
+// check returns an error if the given options violate the constraint. The
+// given options are mapped to their indices in the argument list.
+func (c *constraint) check(given map[*optDef]int) error {
+	e := &ErrConstraint{Constraint: c.kind}
+	for _, o := range c.opts {
+		e.Opts = append(e.Opts, optName(o))
+	}
+	addGiven := func(o *optDef) {
+		e.Given = append(e.Given, optName(o))
+		e.ArgvIndices = append(e.ArgvIndices, given[o])
+	}
+
+	switch c.kind {
+	case MutuallyExclusive, AtLeastOne, ExactlyOne:
+		for _, o := range c.opts {
+			if _, ok := given[o]; ok {
+				addGiven(o)
+			}
+		}
+		n := len(e.Given)
+		if (c.kind == MutuallyExclusive && n > 1) ||
+			(c.kind == AtLeastOne && n == 0) ||
+			(c.kind == ExactlyOne && n != 1) {
+			return e
+		}
+	case Requires:
+		if _, ok := given[c.opts[0]]; !ok {
+			return nil
+		}
+		addGiven(c.opts[0])
+		for _, o := range c.opts[1:] {
+			if _, ok := given[o]; !ok {
+				return e
+			}
+		}
+	case ConflictsWith:
+		if _, ok := given[c.opts[0]]; !ok {
+			return nil
+		}
+		addGiven(c.opts[0])
+		for _, o := range c.opts[1:] {
+			if _, ok := given[o]; ok {
+				addGiven(o)
+			}
+		}
+		if len(e.Given) > 1 {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// synopsis returns the constraint as shown in the usage synopsis, ex.
+// "(--file | --url | --stdin)".
+func (c *constraint) synopsis() string {
+	names := make([]string, len(c.opts))
+	for x, o := range c.opts {
+		names[x] = optName(o)
+	}
+	switch c.kind {
+	case MutuallyExclusive:
+		return "[" + strings.Join(names, " | ") + "]"
+	case AtLeastOne:
+		return "(" + strings.Join(names, " | ") + ")..."
+	case ExactlyOne:
+		return "(" + strings.Join(names, " | ") + ")"
+	case Requires:
+		return "[" + strings.Join(names, " ") + "]"
+	}
+	return ""
+}
+
+// Synopsis returns the parser's usage synopsis.
+func (p *parser) Synopsis() string {
+	b := &bytes.Buffer{}
+
+	names := []string{}
+	for c := p; c != nil; c = c.parent {
+		if c.name != "" {
+			names = append([]string{c.name}, names...)
+		}
+	}
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(' ')
+	}
+
+	b.WriteString(p.synopsisArgs())
+	return b.String()
+}
+
+// synopsisArgs returns the part of the usage synopsis that follows the names
+// of the command and its ancestors.
+func (p *parser) synopsisArgs() string {
+	b := &bytes.Buffer{}
+
+	b.WriteString("[options]")
+	for _, c := range p.constraints {
+		if s := c.synopsis(); s != "" {
+			b.WriteByte(' ')
+			b.WriteString(s)
+		}
+	}
+
+	if len(p.commands) > 0 {
+		b.WriteString(" <command>")
+	}
+
+	return b.String()
+}
+
+// checkConstraints sends an ErrConstraint for each of the constraints of the
+// invoked parsers that is violated by the given options. Options sent with
+// their default values are not considered given.
+func (l *stateList) checkConstraints() {
+	given := map[*optDef]int{}
+	if l.prev != nil {
+		for ps := l.prev.first; ps != nil; ps = ps.next {
+			po, ok := ps.value.(*parsedOpt)
+			if !ok || po.source == FromDefault {
+				continue
+			}
+			if _, ok := given[po.def]; !ok {
+				given[po.def] = po.argvIndex
+			}
+		}
+	}
+
+	for _, p := range l.parsers {
+		for _, c := range p.constraints {
+			if err := c.check(given); err != nil {
+				l.send(&parserState{value: err})
+			}
+		}
+	}
+}
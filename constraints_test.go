@@ -0,0 +1,179 @@
+package gotopt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func constraintErrs(ps ParserState) []*ErrConstraint {
+	errs := []*ErrConstraint{}
+	if ps == nil {
+		return errs
+	}
+	for ps = ps.First(); ps != nil; ps, _ = ps.Next() {
+		if e, ok := ps.Value().(*ErrConstraint); ok {
+			errs = append(errs, e)
+		}
+	}
+	return errs
+}
+
+func TestParserExactlyOne(t *testing.T) {
+	p := NewParser()
+	p.Opt('f', "file", RequiredArgument, "", "")
+	p.Opt('u', "url", RequiredArgument, "", "")
+	p.Opt(0, "stdin", NoArgument, "", "")
+	p.Opt('v', "", NoArgument, "", "")
+	p.ExactlyOne("file", "--url", "stdin")
+
+	ps, err := p.ParseAll([]string{"tpconstraint01", "-v", "effie"})
+	assert.NoError(t, err)
+	errs := constraintErrs(ps)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ExactlyOne, errs[0].Constraint)
+	assert.Empty(t, errs[0].Given)
+	assert.EqualError(t, errs[0],
+		"exactly one of the options '--file', '--url', '--stdin' is required")
+
+	ps, err = p.ParseAll([]string{
+		"tpconstraint01", "-vfa", "effie", "--url", "b", "-f", "c"})
+	assert.NoError(t, err)
+	errs = constraintErrs(ps)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, []string{"--file", "--url"}, errs[0].Given)
+	assert.Equal(t, []int{1, 3}, errs[0].ArgvIndices)
+	assert.EqualError(t, errs[0], "only one of the options "+
+		"'--file' (argv[1]), '--url' (argv[3]) may be given")
+
+	ps, err = p.ParseAll([]string{"tpconstraint01", "--stdin"})
+	assert.NoError(t, err)
+	assert.Empty(t, constraintErrs(ps))
+}
+
+func TestParserMutuallyExclusive(t *testing.T) {
+	p := NewParser()
+	p.Opt('f', "file", RequiredArgument, "", "")
+	p.Opt('u', "url", RequiredArgument, "", "")
+	p.MutuallyExclusive("-f", "u")
+
+	ps, err := p.ParseAll([]string{"tpconstraint02"})
+	assert.NoError(t, err)
+	assert.Empty(t, constraintErrs(ps))
+
+	ps, err = p.ParseAll([]string{"tpconstraint02", "-u", "a", "--file=b"})
+	assert.NoError(t, err)
+	errs := constraintErrs(ps)
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "options "+
+		"'--file' (argv[3]), '--url' (argv[1]) are mutually exclusive")
+}
+
+func TestParserAtLeastOne(t *testing.T) {
+	p := NewParser()
+	p.Opt('f', "file", RequiredArgument, "", "")
+	p.Opt('u', "url", RequiredArgument, "", "")
+	p.AtLeastOne("file", "url")
+
+	ps, err := p.ParseAll([]string{"tpconstraint03", "-fa", "-ub"})
+	assert.NoError(t, err)
+	assert.Empty(t, constraintErrs(ps))
+
+	ps, err = p.ParseAll([]string{"tpconstraint03"})
+	assert.NoError(t, err)
+	errs := constraintErrs(ps)
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0],
+		"at least one of the options '--file', '--url' is required")
+}
+
+func TestParserRequires(t *testing.T) {
+	defer setTestEnv("GOTOPT_TEST_PASSWORD", "secret")()
+
+	p := NewParser()
+	p.Opt(0, "user", RequiredArgument, "", "")
+	p.Opt(0, "password", RequiredArgument, "", "")
+	p.Opt('v', "", NoArgument, "", "")
+	p.Requires("user", "password")
+
+	ps, err := p.ParseAll([]string{"tpconstraint04", "-v", "--user", "a"})
+	assert.NoError(t, err)
+	errs := constraintErrs(ps)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, []int{2}, errs[0].ArgvIndices)
+	assert.EqualError(t, errs[0],
+		"option '--user' (argv[2]) requires '--password'")
+
+	p = NewParser()
+	p.Opt(0, "user", RequiredArgument, "", "")
+	p.Opt(0, "pass", RequiredArgument, "", "", Env("GOTOPT_TEST_PASSWORD"))
+	p.Requires("user", "pass")
+	ps, err = p.ParseAll([]string{"tpconstraint04", "--user", "a"})
+	assert.NoError(t, err)
+	assert.Empty(t, constraintErrs(ps))
+}
+
+func TestParserConflictsWith(t *testing.T) {
+	p := NewParser()
+	p.Opt(0, "user", RequiredArgument, "", "")
+	p.Opt(0, "password", RequiredArgument, "", "")
+	p.Opt(0, "token", RequiredArgument, "", "", Default("none"))
+	p.ConflictsWith("token", "user", "password")
+
+	ps, err := p.ParseAll([]string{"tpconstraint05", "--user", "a"})
+	assert.NoError(t, err)
+	assert.Empty(t, constraintErrs(ps))
+
+	ps, err = p.ParseAll([]string{
+		"tpconstraint05", "--user", "a", "--token", "b"})
+	assert.NoError(t, err)
+	errs := constraintErrs(ps)
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0],
+		"option '--token' (argv[3]) conflicts with '--user' (argv[1])")
+}
+
+func TestParserConstraintCommand(t *testing.T) {
+	p := NewParser()
+	p.Opt('v', "verbose", NoArgument, "", "", Persistent())
+	p.Opt('q', "quiet", NoArgument, "", "", Persistent())
+	p.MutuallyExclusive("verbose", "quiet")
+	c := p.Command("remote", "")
+	c.Opt('f', "file", RequiredArgument, "", "")
+
+	ps, err := p.ParseAll([]string{"tpconstraint06", "-v", "remote", "-q"})
+	assert.NoError(t, err)
+	errs := constraintErrs(ps)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, []int{1, 3}, errs[0].ArgvIndices)
+
+	assert.Panics(t, func() { c.ExactlyOne("file", "nope") })
+	assert.Panics(t, func() { c.ExactlyOne("file") })
+}
+
+func TestParserSynopsis(t *testing.T) {
+	p := NewParser()
+	p.Opt('f', "file", RequiredArgument, "", "")
+	p.Opt('u', "url", RequiredArgument, "", "")
+	p.Opt(0, "stdin", NoArgument, "", "")
+	p.Opt(0, "user", RequiredArgument, "", "")
+	p.Opt(0, "password", RequiredArgument, "", "")
+	p.Opt(0, "token", RequiredArgument, "", "", Default("none"))
+	p.Opt('v', "", NoArgument, "", "")
+	p.ExactlyOne("file", "url", "stdin")
+	p.MutuallyExclusive("v", "token")
+	p.Requires("user", "password")
+	p.ConflictsWith("token", "user")
+	assert.Equal(t,
+		"[options] (--file | --url | --stdin) [-v | --token] "+
+			"[--user --password]",
+		p.Synopsis())
+
+	r := p.Command("remote", "")
+	c := r.Command("add", "")
+	c.Opt(0, "name", RequiredArgument, "", "")
+	c.Opt('f', "", RequiredArgument, "", "")
+	c.AtLeastOne("name", "f")
+	assert.Equal(t, "remote add [options] (--name | -f)...", c.Synopsis())
+	assert.Equal(t, "remote [options] <command>", r.Synopsis())
+}
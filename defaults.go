@@ -0,0 +1,41 @@
+package gotopt
+
+import "strconv"
+
+// Default sets the option's default value. The default value is shown in
+// the option's usage, and if the option is not parsed from the argument list,
+// read from the environment, or read from a configuration file, the option is
+// sent to the client with the default value as its argument and FromDefault
+// as its source.
+//
+// The default value of an option that does not take an argument must be a
+// value accepted by strconv.ParseBool. The option is sent only if the value
+// is true.
+func Default(value string) OptModifier {
+	return func(o *optDef) {
+		o.defValue = value
+		o.hasDefault = true
+	}
+}
+
+// parseDefaults sends the options with default values that were not parsed
+// from the argument list, read from the environment, or read from a
+// configuration file.
+func (p *parser) parseDefaults(l *stateList) {
+	for _, o := range p.optsOrdered {
+		if !o.hasDefault || !o.enabled() {
+			continue
+		}
+		if _, parsed := l.optIndices[o]; parsed {
+			continue
+		}
+		val := o.defValue
+		if o.optType == NoArgument {
+			if b, _ := strconv.ParseBool(val); !b {
+				continue
+			}
+			val = ""
+		}
+		l.sendOpt(newParsedOptState(o, val, false, FromDefault, l.optIndices))
+	}
+}
@@ -0,0 +1,96 @@
+package gotopt
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParserDefault(t *testing.T) {
+	var d time.Duration
+	p := NewParser()
+	p.Opt('n', "name", NoArgument, "", "", Default("true"))
+	p.Opt('q', "quiet", NoArgument, "", "", Default("false"))
+	p.DurationVar(&d, 't', "time", "", "", Default("30s"))
+	p.Opt('x', "xist", OptionalArgument, "", "", Default("play"))
+
+	ps, err := p.ParseAll([]string{"tpdefault01", "-xwork", "effie"})
+	assert.NoError(t, err)
+	ps = ps.First()
+
+	o := ps.Value().(Option)
+	assert.EqualValues(t, 'x', o.Opt())
+	assert.Equal(t, "work", o.Value())
+	assert.Equal(t, FromArgv, o.Source())
+
+	ps, _ = ps.Next()
+	o = ps.Value().(Option)
+	assert.EqualValues(t, 'n', o.Opt())
+	assert.Equal(t, FromDefault, o.Source())
+
+	ps, _ = ps.Next()
+	o = ps.Value().(Option)
+	assert.EqualValues(t, 't', o.Opt())
+	assert.Equal(t, "30s", o.Value())
+	assert.Equal(t, FromDefault, o.Source())
+	assert.Equal(t, 30*time.Second, d)
+
+	ps, _ = ps.Next()
+	assert.Equal(t, []string{"effie"}, ps.Value())
+	assert.Empty(t, ps.LookupOpt('q'))
+}
+
+func TestParserDefaultOverride(t *testing.T) {
+	defer setTestEnv("GOTOPT_TEST_TIME", "37s")()
+
+	p := NewParser()
+	p.Opt('t', "time", RequiredArgument, "", "",
+		Env("GOTOPT_TEST_TIME"), Default("30s"))
+	p.Opt('x', "xist", RequiredArgument, "", "", Default("play"))
+	assert.NoError(t, p.LoadConfigFrom(
+		strings.NewReader("xist=work"), "tpdefault02", IniConfig))
+
+	ps, err := p.ParseAll([]string{"tpdefault02"})
+	assert.NoError(t, err)
+
+	o := ps.LookupOpt('t')[0].Value().(Option)
+	assert.Equal(t, "37s", o.Value())
+	assert.Equal(t, FromEnv, o.Source())
+
+	xs := ps.LookupOpt('x')
+	assert.Len(t, xs, 1)
+	assert.Equal(t, "work", xs[0].Value().(Option).Value())
+	assert.Equal(t, FromConfig, xs[0].Value().(Option).Source())
+}
+
+func TestParserDefaultPersistent(t *testing.T) {
+	var v string
+	p := NewParser()
+	p.StringVar(&v, 'v', "value", "", "", Default("x"), Persistent())
+	p.Command("run", "Run the thing")
+
+	// the option is given after the command's name
+	ps, err := p.ParseAll([]string{"tpdefault03", "run", "-v", "y"})
+	assert.NoError(t, err)
+
+	vs := ps.LookupOpt('v')
+	assert.Len(t, vs, 1)
+	o := vs[0].Value().(Option)
+	assert.Equal(t, "y", o.Value())
+	assert.Equal(t, FromArgv, o.Source())
+	assert.Equal(t, 1, o.Count())
+	assert.Equal(t, "y", v)
+
+	r, err := p.ParseResult([]string{"tpdefault03", "run"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"x"}, r.All('v'))
+	assert.Equal(t, "x", v)
+}
+
+func TestPrintUsageDefault(t *testing.T) {
+	p := NewParser()
+	p.Opt('t', "time", RequiredArgument, "", "the time", Default("30s"))
+	assert.Contains(t, p.Usage(), "the time (default: 30s)")
+}
@@ -0,0 +1,26 @@
+package gotopt
+
+import "fmt"
+
+// DeprecationWarning is sent in the ParserState stream immediately before an
+// option that is deprecated.
+type DeprecationWarning struct {
+	Opt      int
+	LongName string
+
+	// Replacement is the option that replaces the deprecated option, ex.
+	// "--output", or an empty string if the option has no replacement.
+	Replacement string
+}
+
+func (w *DeprecationWarning) String() string {
+	name := fmt.Sprintf("--%s", w.LongName)
+	if w.LongName == "" {
+		name = fmt.Sprintf("-%c", w.Opt)
+	}
+	if w.Replacement == "" {
+		return fmt.Sprintf("option '%s' is deprecated", name)
+	}
+	return fmt.Sprintf(
+		"option '%s' is deprecated, use '%s' instead", name, w.Replacement)
+}
@@ -0,0 +1,99 @@
+package gotopt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParserHidden(t *testing.T) {
+	p := NewParser()
+	p.Opt('n', "name", NoArgument, "", "The name")
+	p.Opt('N', "old-name", NoArgument, "", "The old name", Hidden())
+
+	ps, err := p.ParseAll([]string{"tphidden01", "--old-name"})
+	assert.NoError(t, err)
+	o := ps.First().Value().(Option)
+	assert.Equal(t, "old-name", o.LongName())
+
+	assert.Equal(t, "    -n, --name The name\n", p.Usage())
+	assert.Equal(t, []string{"--name"}, p.Completions([]string{"--"}))
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, p.WriteMarkdown(b))
+	assert.NotContains(t, b.String(), "old-name")
+}
+
+func TestParserDeprecated(t *testing.T) {
+	defer setTestEnv("GOTOPT_TEST_COLOUR", "never")()
+
+	p := NewParser()
+	p.Opt('o', "output", RequiredArgument, "file", "The output file")
+	p.Opt('f', "file", RequiredArgument, "", "The output file",
+		Deprecated("--output"))
+	p.Opt(0, "colour", RequiredArgument, "when", "",
+		Deprecated(""), Env("GOTOPT_TEST_COLOUR"))
+
+	ps, err := p.ParseAll([]string{"tpdeprecated01", "-f", "out", "-o", "x"})
+	assert.NoError(t, err)
+	ps = ps.First()
+
+	w := ps.Value().(*DeprecationWarning)
+	assert.Equal(t, &DeprecationWarning{'f', "file", "--output"}, w)
+	assert.Equal(t,
+		"option '--file' is deprecated, use '--output' instead", w.String())
+
+	ps, _ = ps.Next()
+	o := ps.Value().(Option)
+	assert.EqualValues(t, 'f', o.Opt())
+	assert.Equal(t, "out", o.Value())
+
+	ps, _ = ps.Next()
+	o = ps.Value().(Option)
+	assert.EqualValues(t, 'o', o.Opt())
+
+	ps, _ = ps.Next()
+	w = ps.Value().(*DeprecationWarning)
+	assert.Equal(t, "option '--colour' is deprecated", w.String())
+
+	ps, _ = ps.Next()
+	o = ps.Value().(Option)
+	assert.Equal(t, "colour", o.LongName())
+	assert.Equal(t, FromEnv, o.Source())
+
+	_, ok := ps.Next()
+	assert.False(t, ok)
+
+	exp := `    -o, --output file The output file
+    -f, --file arg    The output file (deprecated: use --output)
+        --colour when (deprecated) [$GOTOPT_TEST_COLOUR]
+`
+	assert.Equal(t, exp, p.Usage())
+}
+
+func TestParserExperimental(t *testing.T) {
+	p := NewParser()
+	p.Opt('n', "name", NoArgument, "", "The name")
+	p.Opt('j', "jit", NoArgument, "", "Enable the JIT",
+		Experimental("GOTOPT_TEST_EXPERIMENTAL"), Default("true"))
+
+	ps, err := p.ParseAll([]string{"tpexperimental01", "-j", "--jit"})
+	assert.NoError(t, err)
+	ps = ps.First()
+	assert.Equal(t, &ErrUnknownOpt{'j', ""}, ps.Value())
+	ps, _ = ps.Next()
+	assert.Equal(t, &ErrUnknownOpt{0, "jit"}, ps.Value())
+	_, ok := ps.Next()
+	assert.False(t, ok)
+	assert.Equal(t, "    -n, --name The name\n", p.Usage())
+
+	defer setTestEnv("GOTOPT_TEST_EXPERIMENTAL", "1")()
+
+	ps, err = p.ParseAll([]string{"tpexperimental02", "-j"})
+	assert.NoError(t, err)
+	o := ps.First().Value().(Option)
+	assert.EqualValues(t, 'j', o.Opt())
+	assert.Equal(t, FromArgv, o.Source())
+	assert.Contains(t, p.Usage(), "--jit")
+}
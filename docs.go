@@ -97,6 +97,25 @@ in a shell from inside a cloned version of this repository:
 
   $ go test -run ^TestGotOptParserParse$ -v
 
+Parsing happens on a goroutine that waits for each ParserState to be received
+before parsing the next one. A client that may stop receiving before the
+channel is closed, ex. on the first error, should use ParseContext and cancel
+the context so the goroutine exits:
+
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+
+    c, _ := p.ParseContext(ctx, os.Args)
+
+    for ps := range c {
+        if err, ok := ps.Value().(error); ok {
+            return err
+        }
+    }
+
+The channel is always closed once parsing stops, and SetParseBuffer may be
+used to give the channel a buffer.
+
 The Parser interface also defines the function ParseAll. Using Parse internally,
 ParseAll doesn't return until all of the options and their arguments have been
 parsed. ParseAll returns the last, received ParserState, which can then be used
@@ -136,5 +155,15 @@ The above example can be executed locally by executing the following command
 in a shell from inside a cloned version of this repository:
 
   $ go test -run ^TestGotOptParserParseAll$ -v
+
+Instead of traversing the list, the options may be looked up by name with the
+ParseResult returned by the function ParseResult:
+
+    r, _ := p.ParseResult([]string{"ProgramName", "-nt37", "effie"})
+
+    if r.Has('n') {
+        t, _ := r.Int("time")
+        fmt.Printf("name is %s, time is %d\n", r.Positionals()[0], t)
+    }
 */
 package gotopt
@@ -0,0 +1,83 @@
+package gotopt
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Env sets the name of the environment variable from which an option's value
+// is read when the option is not present in the argument list.
+//
+// Options read from the environment are sent after the options parsed from
+// the argument list, and their Source is FromEnv. An empty variable is
+// treated as unset. The variable of an option that does not take an argument
+// must be a boolean value, ex. "1" or "true", and the option is sent only if
+// the value is true, or if the option is negatable, is sent negated if the
+// value is false.
+func Env(name string) OptModifier {
+	return func(o *optDef) {
+		o.env = name
+	}
+}
+
+// SetEnvPrefix enables environment variable fallback for every option with a
+// long name.
+func (p *parser) SetEnvPrefix(prefix string) {
+	p.envPrefix = prefix
+}
+
+// envVar returns the name of the option's environment variable, or an empty
+// string if the option does not have one.
+func (p *parser) envVar(o *optDef) string {
+	if o.env != "" {
+		return o.env
+	}
+	if o.longName == "" {
+		return ""
+	}
+	for a := p; a != nil; a = a.parent {
+		if a.envPrefix != "" {
+			return a.envPrefix + strings.ToUpper(
+				strings.Replace(o.longName, "-", "_", -1))
+		}
+	}
+	return ""
+}
+
+// parseEnv sends the options that were not parsed from the argument list but
+// whose environment variables are set.
+func (p *parser) parseEnv(l *stateList) {
+	for _, o := range p.optsOrdered {
+		if !o.enabled() {
+			continue
+		}
+		if _, parsed := l.optIndices[o]; parsed {
+			continue
+		}
+		name := p.envVar(o)
+		if name == "" {
+			continue
+		}
+		val := os.Getenv(name)
+		if val == "" {
+			continue
+		}
+		negated := false
+		if o.optType == NoArgument {
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				l.send(&parserState{
+					value: &ErrInvalidArg{o.opt, o.longName, val, err},
+				})
+				continue
+			}
+			if !b && !o.negatable {
+				continue
+			}
+			negated = !b
+			val = ""
+		}
+		l.sendOpt(newParsedOptState(o, val, negated, FromEnv, l.optIndices))
+	}
+}
@@ -0,0 +1,118 @@
+package gotopt
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setTestEnv(kvs ...string) func() {
+	for x := 0; x < len(kvs); x += 2 {
+		os.Setenv(kvs[x], kvs[x+1])
+	}
+	return func() {
+		for x := 0; x < len(kvs); x += 2 {
+			os.Unsetenv(kvs[x])
+		}
+	}
+}
+
+func TestParserEnv(t *testing.T) {
+	defer setTestEnv(
+		"GOTOPT_TEST_TIME", "37",
+		"GOTOPT_TEST_NAME", "true",
+		"GOTOPT_TEST_XIST", "play")()
+
+	var tm string
+	p := NewParser()
+	p.Opt('n', "name", NoArgument, "", "", Env("GOTOPT_TEST_NAME"))
+	p.StringVar(&tm, 't', "time", "", "", Env("GOTOPT_TEST_TIME"))
+	p.Opt('x', "xist", OptionalArgument, "", "", Env("GOTOPT_TEST_XIST"))
+
+	ps, err := p.ParseAll([]string{"tpenv01", "-xwork", "effie"})
+	assert.NoError(t, err)
+	ps = ps.First()
+
+	o := ps.Value().(Option)
+	assert.EqualValues(t, 'x', o.Opt())
+	assert.Equal(t, "work", o.Value())
+	assert.Equal(t, FromArgv, o.Source())
+
+	ps, _ = ps.Next()
+	o = ps.Value().(Option)
+	assert.EqualValues(t, 'n', o.Opt())
+	assert.Equal(t, FromEnv, o.Source())
+
+	ps, _ = ps.Next()
+	o = ps.Value().(Option)
+	assert.EqualValues(t, 't', o.Opt())
+	assert.Equal(t, "37", o.Value())
+	assert.Equal(t, FromEnv, o.Source())
+	assert.Equal(t, "37", tm)
+
+	ps, _ = ps.Next()
+	assert.Equal(t, []string{"effie"}, ps.Value())
+}
+
+func TestParserEnvPrefix(t *testing.T) {
+	defer setTestEnv(
+		"GOTOPT_TEST_DRY_RUN", "false",
+		"GOTOPT_TEST_TIME", "37",
+		"GOTOPT_TEST_FETCH", "1")()
+
+	p := NewParser()
+	p.SetEnvPrefix("GOTOPT_TEST_")
+	p.Opt('n', "dry-run", NoArgument, "", "")
+	p.Opt('t', "time", RequiredArgument, "", "")
+	p.Opt('h', "", NoArgument, "", "")
+	p.Command("remote", "").Opt('f', "fetch", NoArgument, "", "")
+
+	ps, err := p.ParseAll([]string{"tpenv02", "--time=47", "remote"})
+	assert.NoError(t, err)
+	ps = ps.First()
+
+	o := ps.Value().(Option)
+	assert.EqualValues(t, 't', o.Opt())
+	assert.Equal(t, "47", o.Value())
+	assert.Equal(t, FromArgv, o.Source())
+
+	ps, _ = ps.Next()
+	assert.Equal(t, "remote", ps.Value().(Command).Name())
+
+	ps, _ = ps.Next()
+	o = ps.Value().(Option)
+	assert.EqualValues(t, 'f', o.Opt())
+	assert.Equal(t, FromEnv, o.Source())
+
+	_, ok := ps.Next()
+	assert.False(t, ok)
+}
+
+func TestParserEnvInvalidBool(t *testing.T) {
+	defer setTestEnv("GOTOPT_TEST_NAME", "yes please")()
+
+	p := NewParser()
+	p.Opt('n', "name", NoArgument, "", "", Env("GOTOPT_TEST_NAME"))
+
+	ps, err := p.ParseAll([]string{"tpenv03"})
+	assert.NoError(t, err)
+	assert.IsType(t, &ErrInvalidArg{}, ps.Value())
+	e := ps.Value().(*ErrInvalidArg)
+	assert.EqualValues(t, 'n', e.Opt)
+	assert.Equal(t, "yes please", e.Value)
+}
+
+func TestParserEnvUsage(t *testing.T) {
+	p := NewParser()
+	p.SetEnvPrefix("MYTOOL_")
+	p.Opt('n', "dry-run", NoArgument, "", "Do not make any changes")
+	p.Opt('t', "", RequiredArgument, "", "The time")
+	p.Opt('u', "user", RequiredArgument, "", "", Env("USER"))
+
+	exp := `    -n, --dry-run  Do not make any changes [$MYTOOL_DRY_RUN]
+    -t arg         The time
+    -u, --user arg [$USER]
+`
+	assert.Equal(t, exp, p.Usage())
+}
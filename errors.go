@@ -3,17 +3,40 @@ package gotopt
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
-// ErrRequiredArg is the error for when a required argument is missing.
+// ErrRequiredArg is the error for when a required argument is missing. The
+// LongName is the name by which a long option was given.
 type ErrRequiredArg struct {
-	Opt int
+	Opt      int
+	LongName string
 }
 
 func (e *ErrRequiredArg) Error() string {
+	if e.LongName != "" {
+		return fmt.Sprintf("arg required for opt '--%s'", e.LongName)
+	}
 	return fmt.Sprintf("arg required for opt '%c'", e.Opt)
 }
 
+// ErrMissingOpt is the error for when a required option is not given.
+type ErrMissingOpt struct {
+	Opt      int
+	LongName string
+}
+
+func (e *ErrMissingOpt) Error() string {
+	switch {
+	case e.Opt > 0 && e.LongName != "":
+		return fmt.Sprintf(
+			"missing required option '-%c, --%s'", e.Opt, e.LongName)
+	case e.Opt > 0:
+		return fmt.Sprintf("missing required option '-%c'", e.Opt)
+	}
+	return fmt.Sprintf("missing required option '--%s'", e.LongName)
+}
+
 // ErrUnknownOpt is the error for when an unknown option is encountered.
 type ErrUnknownOpt struct {
 	Opt      int
@@ -27,8 +50,164 @@ func (e *ErrUnknownOpt) Error() string {
 	return fmt.Sprintf("unknown option '--%s'", e.LongName)
 }
 
+// ErrInvalidArg is the error for when an option's argument is rejected by the
+// Value to which the option is bound or is outside of the option's range or
+// does not match the option's regular expression.
+type ErrInvalidArg struct {
+	Opt      int
+	LongName string
+	Value    string
+	Err      error
+}
+
+func (e *ErrInvalidArg) Error() string {
+	if e.LongName == "" {
+		return fmt.Sprintf(
+			"invalid arg '%s' for option '-%c': %v", e.Value, e.Opt, e.Err)
+	}
+	return fmt.Sprintf(
+		"invalid arg '%s' for option '--%s': %v", e.Value, e.LongName, e.Err)
+}
+
+// ErrInvalidChoice is the error for when an option's argument is not one of
+// the option's choices or is an ambiguous abbreviation of more than one of
+// them.
+type ErrInvalidChoice struct {
+	Opt      int
+	LongName string
+	Value    string
+
+	// Choices are the option's valid choices, or if the argument is
+	// ambiguous, the choices the argument abbreviates.
+	Choices []string
+
+	// Ambiguous is a flag indicating the argument is an abbreviation of more
+	// than one of the option's choices.
+	Ambiguous bool
+}
+
+func (e *ErrInvalidChoice) Error() string {
+	name := fmt.Sprintf("--%s", e.LongName)
+	if e.LongName == "" {
+		name = fmt.Sprintf("-%c", e.Opt)
+	}
+	choices := make([]string, len(e.Choices))
+	for x, c := range e.Choices {
+		choices[x] = fmt.Sprintf("'%s'", c)
+	}
+	if e.Ambiguous {
+		return fmt.Sprintf(
+			"ambiguous arg '%s' for option '%s'; possibilities: %s",
+			e.Value, name, strings.Join(choices, " "))
+	}
+	return fmt.Sprintf(
+		"invalid arg '%s' for option '%s'; valid choices: %s",
+		e.Value, name, strings.Join(choices, " "))
+}
+
+// ErrUnknownCommand is the error for when the first non-option argument
+// parsed by a parser with commands is not the name of a command.
+type ErrUnknownCommand struct {
+	Name string
+}
+
+func (e *ErrUnknownCommand) Error() string {
+	return fmt.Sprintf("unknown command '%s'", e.Name)
+}
+
+// ErrConstraint is the error for when the options given to a parser violate
+// one of the parser's constraints.
+type ErrConstraint struct {
+	// Constraint is the type of the violated constraint.
+	Constraint Constraints
+
+	// Opts are the names of the options to which the constraint applies,
+	// ex. "--file" or "-f". The first name of a Requires or ConflictsWith
+	// constraint is the option that requires or conflicts with the others.
+	Opts []string
+
+	// Given are the names of the offending options that were given.
+	Given []string
+
+	// ArgvIndices are the indices in the argument list of the options in
+	// Given. The index of an option that was not parsed from the argument
+	// list is -1.
+	ArgvIndices []int
+}
+
+func (e *ErrConstraint) Error() string {
+	given := make([]string, len(e.Given))
+	for x, name := range e.Given {
+		given[x] = fmt.Sprintf("'%s'", name)
+		if e.ArgvIndices[x] > -1 {
+			given[x] = fmt.Sprintf("%s (argv[%d])", given[x], e.ArgvIndices[x])
+		}
+	}
+	opts := make([]string, len(e.Opts))
+	for x, name := range e.Opts {
+		opts[x] = fmt.Sprintf("'%s'", name)
+	}
+
+	switch e.Constraint {
+	case MutuallyExclusive:
+		return fmt.Sprintf(
+			"options %s are mutually exclusive", strings.Join(given, ", "))
+	case AtLeastOne:
+		return fmt.Sprintf(
+			"at least one of the options %s is required",
+			strings.Join(opts, ", "))
+	case ExactlyOne:
+		if len(given) == 0 {
+			return fmt.Sprintf(
+				"exactly one of the options %s is required",
+				strings.Join(opts, ", "))
+		}
+		return fmt.Sprintf(
+			"only one of the options %s may be given",
+			strings.Join(given, ", "))
+	case Requires:
+		return fmt.Sprintf(
+			"option %s requires %s", given[0], strings.Join(opts[1:], ", "))
+	case ConflictsWith:
+		return fmt.Sprintf(
+			"option %s conflicts with %s",
+			given[0], strings.Join(given[1:], ", "))
+	}
+	return fmt.Sprintf("constraint violated by options %s",
+		strings.Join(given, ", "))
+}
+
+// ErrUnsupportedShell is the error for when a completion script is requested
+// for an unsupported shell.
+type ErrUnsupportedShell struct {
+	Shell string
+}
+
+func (e *ErrUnsupportedShell) Error() string {
+	return fmt.Sprintf("unsupported shell '%s'", e.Shell)
+}
+
+// ErrConfigFile is the error for when a configuration file cannot be read or
+// is invalid.
+type ErrConfigFile struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *ErrConfigFile) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %v", e.File, e.Err)
+	}
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
 var (
 	// ErrEmptyArgList is returned by Parser.Parse and Parser.ParseAll when
 	// there is an empty argument list.
 	ErrEmptyArgList = errors.New("empty arg list")
+
+	// ErrInvalidBindTarget is returned by Parser.Bind and NewParserFor when
+	// the value to bind is not a pointer to a struct.
+	ErrInvalidBindTarget = errors.New("bind target must be a struct pointer")
 )
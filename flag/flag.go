@@ -0,0 +1,945 @@
+/*
+Package flag is a drop-in replacement for the golang stdlib "flag" package
+(https://goo.gl/gKusxh) that is built on top of gotopt.Parser.
+
+Programs that use the stdlib package can switch to this one by changing the
+import path. The call sites stay the same, but the command line is parsed with
+GNU getopt semantics:
+
+  - Flags with single-character names are short options, ex. -v. Short
+    options may be clustered, ex. -vn, and a short option's argument may be
+    attached to it, ex. -t37.
+
+  - Flags with multi-character names are long options, ex. --time. A long
+    option's argument may be given as --time=37 or --time 37, and a long
+    option may be abbreviated as long as the abbreviation is unambiguous.
+
+  - Options and non-option arguments may be intermixed. The argument "--"
+    terminates option parsing.
+
+  - Boolean short options never take an argument. Boolean long options take
+    an optional argument, ex. --verbose=false.
+
+The package also implements the API of the de facto replacement for the
+stdlib package, "pflag" (https://goo.gl/9wvL9j). Flags defined with the
+pflag functions, ex. StringP, have both a long name and a one-letter
+shorthand.
+*/
+package flag
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akutz/gotopt"
+)
+
+// ErrHelp is the error returned if the -h or --help flag is invoked but no
+// such flag is defined.
+var ErrHelp = errors.New("flag: help requested")
+
+// Value is the interface to the dynamic value stored in a flag.
+type Value interface {
+	String() string
+	Set(string) error
+}
+
+// Getter is an interface that allows the contents of a Value to be retrieved.
+type Getter interface {
+	Value
+	Get() interface{}
+}
+
+// boolFlag is implemented by Values for flags that never take an argument.
+type boolFlag interface {
+	Value
+	IsBoolFlag() bool
+}
+
+// ErrorHandling defines how FlagSet.Parse behaves if the parse fails.
+type ErrorHandling int
+
+const (
+	// ContinueOnError causes Parse to return a descriptive error.
+	ContinueOnError ErrorHandling = iota
+
+	// ExitOnError causes Parse to call os.Exit(2).
+	ExitOnError
+
+	// PanicOnError causes Parse to panic with a descriptive error.
+	PanicOnError
+)
+
+// Flag represents the state of a flag.
+type Flag struct {
+	Name                string // name as it appears on command line
+	Shorthand           string // one-letter abbreviated flag
+	Usage               string // help message
+	Value               Value  // value as set
+	DefValue            string // default value (as text); for usage message
+	Changed             bool   // if the user set the value
+	NoOptDefVal         string // value used if the flag is given without an argument
+	Deprecated          string // if not empty, the flag is deprecated; what to use instead
+	Hidden              bool   // whether or not the flag is omitted from usage
+	ShorthandDeprecated string // if not empty, the shorthand is deprecated; what to use instead
+}
+
+// shortOpt returns the option character used to parse the flag as a short
+// option, or zero if the flag has no short option.
+func (fl *Flag) shortOpt() byte {
+	if fl.Shorthand != "" {
+		return fl.Shorthand[0]
+	}
+	if isShort(fl.Name) {
+		return fl.Name[0]
+	}
+	return 0
+}
+
+// longOpt returns the name used to parse the flag as a long option, or an
+// empty string if the flag has no long option.
+func (fl *Flag) longOpt() string {
+	if fl.Shorthand == "" && isShort(fl.Name) {
+		return ""
+	}
+	return fl.Name
+}
+
+// optTypes returns the argument types of the flag's short and long options.
+//
+// A flag with a NoOptDefVal takes an optional argument. The exception is the
+// short option of a boolean flag, which never takes an argument so that it
+// may be clustered with other short options.
+func (fl *Flag) optTypes() (short, long gotopt.OptionTypes) {
+	if fl.NoOptDefVal == "" {
+		return gotopt.RequiredArgument, gotopt.RequiredArgument
+	}
+	if bf, ok := fl.Value.(boolFlag); ok && bf.IsBoolFlag() {
+		return gotopt.NoArgument, gotopt.OptionalArgument
+	}
+	return gotopt.OptionalArgument, gotopt.OptionalArgument
+}
+
+// FlagSet represents a set of defined flags.
+type FlagSet struct {
+	// Usage is the function called when an error occurs while parsing flags.
+	Usage func()
+
+	name              string
+	parsed            bool
+	actual            map[string]*Flag
+	formal            map[string]*Flag
+	shorthands        map[byte]*Flag
+	args              []string
+	errorHandling     ErrorHandling
+	output            io.Writer
+	normalizeNameFunc func(f *FlagSet, name string) NormalizedName
+}
+
+// NewFlagSet returns a new, empty flag set with the specified name and error
+// handling property.
+func NewFlagSet(name string, errorHandling ErrorHandling) *FlagSet {
+	f := &FlagSet{
+		name:          name,
+		errorHandling: errorHandling,
+	}
+	f.Usage = f.defaultUsage
+	return f
+}
+
+// Init sets the name and error handling property for a flag set.
+func (f *FlagSet) Init(name string, errorHandling ErrorHandling) {
+	f.name = name
+	f.errorHandling = errorHandling
+}
+
+// Name returns the name of the flag set.
+func (f *FlagSet) Name() string {
+	return f.name
+}
+
+// ErrorHandling returns the error handling behavior of the flag set.
+func (f *FlagSet) ErrorHandling() ErrorHandling {
+	return f.errorHandling
+}
+
+// Output returns the destination for usage and error messages. os.Stderr is
+// returned if output was not set or was set to nil.
+func (f *FlagSet) Output() io.Writer {
+	if f.output == nil {
+		return os.Stderr
+	}
+	return f.output
+}
+
+// SetOutput sets the destination for usage and error messages. If output is
+// nil, os.Stderr is used.
+func (f *FlagSet) SetOutput(output io.Writer) {
+	f.output = output
+}
+
+// sortFlags returns the flags as a slice in lexicographical sorted order.
+func sortFlags(flags map[string]*Flag) []*Flag {
+	list := make([]*Flag, 0, len(flags))
+	for _, fl := range flags {
+		list = append(list, fl)
+	}
+	sort.Sort(flagsByName(list))
+	return list
+}
+
+type flagsByName []*Flag
+
+func (l flagsByName) Len() int           { return len(l) }
+func (l flagsByName) Less(i, j int) bool { return l[i].Name < l[j].Name }
+func (l flagsByName) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+
+// VisitAll visits the flags in lexicographical order, calling fn for each.
+// It visits all flags, even those not set.
+func (f *FlagSet) VisitAll(fn func(*Flag)) {
+	for _, fl := range sortFlags(f.formal) {
+		fn(fl)
+	}
+}
+
+// Visit visits the flags in lexicographical order, calling fn for each. It
+// visits only those flags that have been set.
+func (f *FlagSet) Visit(fn func(*Flag)) {
+	for _, fl := range sortFlags(f.actual) {
+		fn(fl)
+	}
+}
+
+// Lookup returns the Flag structure of the named flag, returning nil if none
+// exists.
+func (f *FlagSet) Lookup(name string) *Flag {
+	return f.formal[f.normalizeFlagName(name)]
+}
+
+// Set sets the value of the named flag.
+func (f *FlagSet) Set(name, value string) error {
+	name = f.normalizeFlagName(name)
+	fl, ok := f.formal[name]
+	if !ok {
+		return fmt.Errorf("no such flag %s", dashes(name))
+	}
+	if err := fl.Value.Set(value); err != nil {
+		return err
+	}
+	f.markChanged(fl)
+	return nil
+}
+
+// markChanged records that the flag's value was set.
+func (f *FlagSet) markChanged(fl *Flag) {
+	if f.actual == nil {
+		f.actual = map[string]*Flag{}
+	}
+	f.actual[fl.Name] = fl
+	fl.Changed = true
+}
+
+// dashes returns the flag name prefixed with one dash if the name is a short
+// option or two dashes if the name is a long option.
+func dashes(name string) string {
+	if isShort(name) {
+		return "-" + name
+	}
+	return "--" + name
+}
+
+// isShort returns a flag indicating whether or not the flag name is parsed
+// as a short option.
+func isShort(name string) bool {
+	return len(name) == 1
+}
+
+// isZeroValue determines whether the string represents the zero value for a
+// flag.
+func isZeroValue(fl *Flag, value string) bool {
+	// Build a zero value of the flag's Value type, and see if the result of
+	// calling its String method equals the value passed in. This works unless
+	// the Value type is itself an interface type.
+	typ := reflect.TypeOf(fl.Value)
+	var z reflect.Value
+	if typ.Kind() == reflect.Ptr {
+		z = reflect.New(typ.Elem())
+	} else {
+		z = reflect.Zero(typ)
+	}
+	if value == z.Interface().(Value).String() {
+		return true
+	}
+	switch value {
+	case "false", "", "0":
+		return true
+	}
+	return false
+}
+
+// UnquoteUsage extracts a back-quoted name from the usage string for a flag
+// and returns it and the un-quoted usage. Given "a `name` to show" it returns
+// ("name", "a name to show"). If there are no back quotes, the name is an
+// educated guess of the type of the flag's value, or the empty string if the
+// flag is boolean.
+func UnquoteUsage(fl *Flag) (name string, usage string) {
+	usage = fl.Usage
+	for i := 0; i < len(usage); i++ {
+		if usage[i] == '`' {
+			for j := i + 1; j < len(usage); j++ {
+				if usage[j] == '`' {
+					name = usage[i+1 : j]
+					usage = usage[:i] + name + usage[j+1:]
+					return name, usage
+				}
+			}
+			break
+		}
+	}
+	name = "value"
+	switch fl.Value.(type) {
+	case boolFlag:
+		name = ""
+	case *durationValue:
+		name = "duration"
+	case *float64Value:
+		name = "float"
+	case *intValue, *int64Value:
+		name = "int"
+	case *stringValue:
+		name = "string"
+	case *uintValue, *uint64Value:
+		name = "uint"
+	}
+	return
+}
+
+// PrintDefaults prints, to standard error unless configured otherwise, the
+// default values of all defined flags in the set.
+func (f *FlagSet) PrintDefaults() {
+	f.VisitAll(func(fl *Flag) {
+		if fl.Hidden || fl.Deprecated != "" {
+			return
+		}
+		b := &bytes.Buffer{}
+		if fl.Shorthand != "" && fl.ShorthandDeprecated == "" {
+			fmt.Fprintf(b, "  -%s, --%s", fl.Shorthand, fl.Name)
+		} else {
+			fmt.Fprintf(b, "  %s", dashes(fl.Name))
+		}
+		name, usage := UnquoteUsage(fl)
+		if len(name) > 0 {
+			b.WriteString(" ")
+			b.WriteString(name)
+			if fl.NoOptDefVal != "" {
+				if _, ok := fl.Value.(*stringValue); ok {
+					fmt.Fprintf(b, "[=%q]", fl.NoOptDefVal)
+				} else {
+					fmt.Fprintf(b, "[=%s]", fl.NoOptDefVal)
+				}
+			}
+		}
+		// Short options without an argument fit on one line with the usage.
+		if isShort(fl.Name) && b.Len() <= 4 {
+			b.WriteString("\t")
+		} else {
+			b.WriteString("\n    \t")
+		}
+		b.WriteString(strings.Replace(usage, "\n", "\n    \t", -1))
+		if !isZeroValue(fl, fl.DefValue) {
+			if _, ok := fl.Value.(*stringValue); ok {
+				fmt.Fprintf(b, " (default %q)", fl.DefValue)
+			} else {
+				fmt.Fprintf(b, " (default %v)", fl.DefValue)
+			}
+		}
+		fmt.Fprintln(f.Output(), b.String())
+	})
+}
+
+// defaultUsage is the default function to print a usage message.
+func (f *FlagSet) defaultUsage() {
+	if f.name == "" {
+		fmt.Fprintf(f.Output(), "Usage:\n")
+	} else {
+		fmt.Fprintf(f.Output(), "Usage of %s:\n", f.name)
+	}
+	f.PrintDefaults()
+}
+
+// NFlag returns the number of flags that have been set.
+func (f *FlagSet) NFlag() int {
+	return len(f.actual)
+}
+
+// Arg returns the i'th argument. Arg(0) is the first remaining argument after
+// flags have been processed. Arg returns an empty string if the requested
+// element does not exist.
+func (f *FlagSet) Arg(i int) string {
+	if i < 0 || i >= len(f.args) {
+		return ""
+	}
+	return f.args[i]
+}
+
+// NArg is the number of arguments remaining after flags have been processed.
+func (f *FlagSet) NArg() int {
+	return len(f.args)
+}
+
+// Args returns the non-flag arguments.
+func (f *FlagSet) Args() []string {
+	return f.args
+}
+
+// Var defines a flag with the specified name and usage string. The type and
+// value of the flag are represented by the first argument, of type Value,
+// which typically holds a user-defined implementation of Value.
+func (f *FlagSet) Var(value Value, name string, usage string) {
+	f.VarPF(value, name, "", usage)
+}
+
+// AddFlag adds the flag to the flag set.
+func (f *FlagSet) AddFlag(fl *Flag) {
+	name := f.normalizeFlagName(fl.Name)
+	if name == "" || strings.HasPrefix(name, "-") || strings.Contains(name, "=") {
+		panic(fmt.Sprintf("flag %q has an invalid name", name))
+	}
+	if _, alreadythere := f.formal[name]; alreadythere {
+		f.panicf("flag redefined: %s", name)
+	}
+	fl.Name = name
+
+	if fl.NoOptDefVal == "" {
+		if bf, ok := fl.Value.(boolFlag); ok && bf.IsBoolFlag() {
+			fl.NoOptDefVal = "true"
+		}
+	}
+
+	if c := fl.shortOpt(); c > 0 {
+		if len(fl.Shorthand) > 1 {
+			f.panicf("%q shorthand is more than one ASCII character", fl.Shorthand)
+		}
+		if used, alreadythere := f.shorthands[c]; alreadythere {
+			f.panicf(
+				"unable to redefine %q shorthand in %q flagset: "+
+					"it's already used for %q flag", c, f.name, used.Name)
+		}
+		if f.shorthands == nil {
+			f.shorthands = map[byte]*Flag{}
+		}
+		f.shorthands[c] = fl
+	}
+
+	if f.formal == nil {
+		f.formal = map[string]*Flag{}
+	}
+	f.formal[name] = fl
+}
+
+// panicf prints to standard error a formatted message prefixed with the flag
+// set's name and then panics with that message.
+func (f *FlagSet) panicf(format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	if f.name != "" {
+		msg = fmt.Sprintf("%s %s", f.name, msg)
+	}
+	fmt.Fprintln(f.Output(), msg)
+	panic(msg)
+}
+
+// failf prints to standard error a formatted error and usage message and
+// returns the error.
+func (f *FlagSet) failf(format string, a ...interface{}) error {
+	err := fmt.Errorf(format, a...)
+	fmt.Fprintln(f.Output(), err)
+	f.usage()
+	return err
+}
+
+// usage calls the Usage method for the flag set if one is specified, or the
+// appropriate default usage function otherwise.
+func (f *FlagSet) usage() {
+	if f.Usage == nil {
+		f.defaultUsage()
+	} else {
+		f.Usage()
+	}
+}
+
+// newParser returns a gotopt.Parser with an option registered for each of
+// the flag set's flags.
+//
+// If the flag set does not define a flag named "help", the option --help is
+// registered as well so that it can be reported as ErrHelp. This also ensures
+// the parser always has at least one long option, without which the getopt
+// loop would not recognize unknown long options as such.
+func (f *FlagSet) newParser() gotopt.Parser {
+	p := gotopt.NewParser()
+	if _, ok := f.formal["help"]; !ok {
+		p.Opt(0, "help", gotopt.NoArgument, "", "")
+	}
+	for _, fl := range sortFlags(f.formal) {
+		c, name := fl.shortOpt(), fl.longOpt()
+		shortType, longType := fl.optTypes()
+		// a deprecated shorthand is its own option so that its use is known
+		if c > 0 && name != "" && shortType == longType &&
+			fl.ShorthandDeprecated == "" {
+			p.Opt(int(c), name, longType, "", fl.Usage)
+			continue
+		}
+		if c > 0 {
+			p.Opt(int(c), "", shortType, "", fl.Usage)
+		}
+		if name != "" {
+			p.Opt(0, name, longType, "", fl.Usage)
+		}
+	}
+	return p
+}
+
+// Parse parses flag definitions from the argument list, which should not
+// include the command name. Must be called after all flags in the FlagSet
+// are defined and before flags are accessed by the program. The return value
+// will be ErrHelp if -h or --help were set but not defined.
+func (f *FlagSet) Parse(arguments []string) error {
+	f.parsed = true
+	err := f.parse(arguments)
+	if err == nil {
+		return nil
+	}
+	switch f.errorHandling {
+	case ExitOnError:
+		if err == ErrHelp {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	case PanicOnError:
+		panic(err)
+	}
+	return err
+}
+
+func (f *FlagSet) parse(arguments []string) error {
+	f.args = []string{}
+	if len(arguments) == 0 {
+		return nil
+	}
+
+	// the getopt loop permutes the argument list, so parse a copy of it with
+	// the flag set's name in the place of the program name
+	argv := make([]string, len(arguments)+1)
+	argv[0] = f.name
+	copy(argv[1:], arguments)
+	f.normalizeArgs(argv)
+
+	ps, err := f.newParser().ParseAll(argv)
+	if err != nil {
+		return err
+	}
+	if ps == nil {
+		return nil
+	}
+
+	for ps = ps.First(); ; {
+		switch tv := ps.Value().(type) {
+		case gotopt.Option:
+			if err := f.parseOpt(tv); err != nil {
+				return err
+			}
+		case []string:
+			f.args = tv
+		case *gotopt.ErrRequiredArg:
+			return f.missingArg(tv)
+		case *gotopt.ErrUnknownOpt:
+			return f.unknownOpt(tv)
+		case error:
+			return f.failf("%v", tv)
+		}
+		var ok bool
+		if ps, ok = ps.Next(); !ok {
+			break
+		}
+	}
+
+	return nil
+}
+
+// parseOpt sets the value of the flag that corresponds to a parsed option.
+func (f *FlagSet) parseOpt(o gotopt.Option) error {
+	var (
+		fl   *Flag
+		name string
+	)
+	if o.LongName() != "" {
+		name = "--" + o.LongName()
+		fl = f.formal[o.LongName()]
+		if fl == nil && o.LongName() == "help" {
+			f.usage()
+			return ErrHelp
+		}
+	} else {
+		name = fmt.Sprintf("-%c", o.Opt())
+		fl = f.shorthands[byte(o.Opt())]
+	}
+	if fl == nil {
+		return f.failf("flag provided but not defined: %s", name)
+	}
+
+	if fl.Deprecated != "" {
+		fmt.Fprintf(f.Output(),
+			"Flag --%s has been deprecated, %s\n", fl.Name, fl.Deprecated)
+	} else if fl.ShorthandDeprecated != "" && o.LongName() == "" {
+		fmt.Fprintf(f.Output(),
+			"Flag shorthand -%s has been deprecated, %s\n",
+			fl.Shorthand, fl.ShorthandDeprecated)
+	}
+
+	value := o.Value()
+	if value == "" && fl.NoOptDefVal != "" {
+		value = fl.NoOptDefVal
+	}
+	if err := fl.Value.Set(value); err != nil {
+		return f.failf("invalid value %q for flag %s: %v", value, name, err)
+	}
+
+	f.markChanged(fl)
+	return nil
+}
+
+// missingArg returns the error for an option whose required argument is
+// missing.
+func (f *FlagSet) missingArg(e *gotopt.ErrRequiredArg) error {
+	if e.LongName != "" {
+		return f.failf("flag needs an argument: --%s", e.LongName)
+	}
+	if e.Opt > 0 {
+		return f.failf("flag needs an argument: -%c", e.Opt)
+	}
+	return f.failf("flag needs an argument")
+}
+
+// unknownOpt returns the error for an option that is not defined by the flag
+// set. ErrHelp is returned for -h and --help.
+func (f *FlagSet) unknownOpt(e *gotopt.ErrUnknownOpt) error {
+	name := e.LongName
+	if i := strings.IndexByte(name, '='); i > -1 {
+		name = name[:i]
+	}
+	if name == "" {
+		if e.Opt == 'h' {
+			f.usage()
+			return ErrHelp
+		}
+		return f.failf("flag provided but not defined: -%c", e.Opt)
+	}
+	return f.failf("flag provided but not defined: --%s", name)
+}
+
+// Parsed reports whether f.Parse has been called.
+func (f *FlagSet) Parsed() bool {
+	return f.parsed
+}
+
+// BoolVar defines a bool flag with specified name, default value, and usage
+// string. The argument p points to a bool variable in which to store the
+// value of the flag.
+func (f *FlagSet) BoolVar(p *bool, name string, value bool, usage string) {
+	f.Var(newBoolValue(value, p), name, usage)
+}
+
+// Bool defines a bool flag with specified name, default value, and usage
+// string. The return value is the address of a bool variable that stores the
+// value of the flag.
+func (f *FlagSet) Bool(name string, value bool, usage string) *bool {
+	p := new(bool)
+	f.BoolVar(p, name, value, usage)
+	return p
+}
+
+// IntVar defines an int flag with specified name, default value, and usage
+// string. The argument p points to an int variable in which to store the
+// value of the flag.
+func (f *FlagSet) IntVar(p *int, name string, value int, usage string) {
+	f.Var(newIntValue(value, p), name, usage)
+}
+
+// Int defines an int flag with specified name, default value, and usage
+// string. The return value is the address of an int variable that stores the
+// value of the flag.
+func (f *FlagSet) Int(name string, value int, usage string) *int {
+	p := new(int)
+	f.IntVar(p, name, value, usage)
+	return p
+}
+
+// Int64Var defines an int64 flag with specified name, default value, and
+// usage string. The argument p points to an int64 variable in which to store
+// the value of the flag.
+func (f *FlagSet) Int64Var(p *int64, name string, value int64, usage string) {
+	f.Var(newInt64Value(value, p), name, usage)
+}
+
+// Int64 defines an int64 flag with specified name, default value, and usage
+// string. The return value is the address of an int64 variable that stores
+// the value of the flag.
+func (f *FlagSet) Int64(name string, value int64, usage string) *int64 {
+	p := new(int64)
+	f.Int64Var(p, name, value, usage)
+	return p
+}
+
+// UintVar defines a uint flag with specified name, default value, and usage
+// string. The argument p points to a uint variable in which to store the
+// value of the flag.
+func (f *FlagSet) UintVar(p *uint, name string, value uint, usage string) {
+	f.Var(newUintValue(value, p), name, usage)
+}
+
+// Uint defines a uint flag with specified name, default value, and usage
+// string. The return value is the address of a uint variable that stores the
+// value of the flag.
+func (f *FlagSet) Uint(name string, value uint, usage string) *uint {
+	p := new(uint)
+	f.UintVar(p, name, value, usage)
+	return p
+}
+
+// Uint64Var defines a uint64 flag with specified name, default value, and
+// usage string. The argument p points to a uint64 variable in which to store
+// the value of the flag.
+func (f *FlagSet) Uint64Var(
+	p *uint64, name string, value uint64, usage string) {
+
+	f.Var(newUint64Value(value, p), name, usage)
+}
+
+// Uint64 defines a uint64 flag with specified name, default value, and usage
+// string. The return value is the address of a uint64 variable that stores
+// the value of the flag.
+func (f *FlagSet) Uint64(name string, value uint64, usage string) *uint64 {
+	p := new(uint64)
+	f.Uint64Var(p, name, value, usage)
+	return p
+}
+
+// StringVar defines a string flag with specified name, default value, and
+// usage string. The argument p points to a string variable in which to store
+// the value of the flag.
+func (f *FlagSet) StringVar(p *string, name string, value string, usage string) {
+	f.Var(newStringValue(value, p), name, usage)
+}
+
+// String defines a string flag with specified name, default value, and usage
+// string. The return value is the address of a string variable that stores
+// the value of the flag.
+func (f *FlagSet) String(name string, value string, usage string) *string {
+	p := new(string)
+	f.StringVar(p, name, value, usage)
+	return p
+}
+
+// Float64Var defines a float64 flag with specified name, default value, and
+// usage string. The argument p points to a float64 variable in which to store
+// the value of the flag.
+func (f *FlagSet) Float64Var(
+	p *float64, name string, value float64, usage string) {
+
+	f.Var(newFloat64Value(value, p), name, usage)
+}
+
+// Float64 defines a float64 flag with specified name, default value, and
+// usage string. The return value is the address of a float64 variable that
+// stores the value of the flag.
+func (f *FlagSet) Float64(name string, value float64, usage string) *float64 {
+	p := new(float64)
+	f.Float64Var(p, name, value, usage)
+	return p
+}
+
+// DurationVar defines a time.Duration flag with specified name, default
+// value, and usage string. The argument p points to a time.Duration variable
+// in which to store the value of the flag. The flag accepts a value
+// acceptable to time.ParseDuration.
+func (f *FlagSet) DurationVar(
+	p *time.Duration, name string, value time.Duration, usage string) {
+
+	f.Var(newDurationValue(value, p), name, usage)
+}
+
+// Duration defines a time.Duration flag with specified name, default value,
+// and usage string. The return value is the address of a time.Duration
+// variable that stores the value of the flag. The flag accepts a value
+// acceptable to time.ParseDuration.
+func (f *FlagSet) Duration(
+	name string, value time.Duration, usage string) *time.Duration {
+
+	p := new(time.Duration)
+	f.DurationVar(p, name, value, usage)
+	return p
+}
+
+// -- bool Value
+type boolValue bool
+
+func newBoolValue(val bool, p *bool) *boolValue {
+	*p = val
+	return (*boolValue)(p)
+}
+
+func (b *boolValue) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*b = boolValue(v)
+	return nil
+}
+
+func (b *boolValue) Get() interface{} { return bool(*b) }
+func (b *boolValue) String() string   { return strconv.FormatBool(bool(*b)) }
+func (b *boolValue) IsBoolFlag() bool { return true }
+
+// -- int Value
+type intValue int
+
+func newIntValue(val int, p *int) *intValue {
+	*p = val
+	return (*intValue)(p)
+}
+
+func (i *intValue) Set(s string) error {
+	v, err := strconv.ParseInt(s, 0, strconv.IntSize)
+	if err != nil {
+		return err
+	}
+	*i = intValue(v)
+	return nil
+}
+
+func (i *intValue) Get() interface{} { return int(*i) }
+func (i *intValue) String() string   { return strconv.Itoa(int(*i)) }
+
+// -- int64 Value
+type int64Value int64
+
+func newInt64Value(val int64, p *int64) *int64Value {
+	*p = val
+	return (*int64Value)(p)
+}
+
+func (i *int64Value) Set(s string) error {
+	v, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		return err
+	}
+	*i = int64Value(v)
+	return nil
+}
+
+func (i *int64Value) Get() interface{} { return int64(*i) }
+func (i *int64Value) String() string   { return strconv.FormatInt(int64(*i), 10) }
+
+// -- uint Value
+type uintValue uint
+
+func newUintValue(val uint, p *uint) *uintValue {
+	*p = val
+	return (*uintValue)(p)
+}
+
+func (i *uintValue) Set(s string) error {
+	v, err := strconv.ParseUint(s, 0, strconv.IntSize)
+	if err != nil {
+		return err
+	}
+	*i = uintValue(v)
+	return nil
+}
+
+func (i *uintValue) Get() interface{} { return uint(*i) }
+func (i *uintValue) String() string   { return strconv.FormatUint(uint64(*i), 10) }
+
+// -- uint64 Value
+type uint64Value uint64
+
+func newUint64Value(val uint64, p *uint64) *uint64Value {
+	*p = val
+	return (*uint64Value)(p)
+}
+
+func (i *uint64Value) Set(s string) error {
+	v, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		return err
+	}
+	*i = uint64Value(v)
+	return nil
+}
+
+func (i *uint64Value) Get() interface{} { return uint64(*i) }
+func (i *uint64Value) String() string   { return strconv.FormatUint(uint64(*i), 10) }
+
+// -- string Value
+type stringValue string
+
+func newStringValue(val string, p *string) *stringValue {
+	*p = val
+	return (*stringValue)(p)
+}
+
+func (s *stringValue) Set(val string) error {
+	*s = stringValue(val)
+	return nil
+}
+
+func (s *stringValue) Get() interface{} { return string(*s) }
+func (s *stringValue) String() string   { return string(*s) }
+
+// -- float64 Value
+type float64Value float64
+
+func newFloat64Value(val float64, p *float64) *float64Value {
+	*p = val
+	return (*float64Value)(p)
+}
+
+func (f *float64Value) Set(s string) error {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	*f = float64Value(v)
+	return nil
+}
+
+func (f *float64Value) Get() interface{} { return float64(*f) }
+func (f *float64Value) String() string   { return strconv.FormatFloat(float64(*f), 'g', -1, 64) }
+
+// -- time.Duration Value
+type durationValue time.Duration
+
+func newDurationValue(val time.Duration, p *time.Duration) *durationValue {
+	*p = val
+	return (*durationValue)(p)
+}
+
+func (d *durationValue) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = durationValue(v)
+	return nil
+}
+
+func (d *durationValue) Get() interface{} { return time.Duration(*d) }
+func (d *durationValue) String() string   { return (*time.Duration)(d).String() }
@@ -0,0 +1,189 @@
+package flag
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestFlagSet() (*FlagSet, *bytes.Buffer) {
+	b := &bytes.Buffer{}
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(b)
+	return f, b
+}
+
+func TestFlagSetParse(t *testing.T) {
+	f, _ := newTestFlagSet()
+	n := f.Bool("n", false, "name is a trailing arg")
+	tm := f.Duration("time", 0, "the time")
+	i := f.Int("i", 1, "an int")
+	s := f.String("str", "def", "a string")
+
+	assert.NoError(t, f.Parse(
+		[]string{"--time=37s", "effie", "-ni3", "--str", "hi", "tom"}))
+	assert.True(t, f.Parsed())
+	assert.True(t, *n)
+	assert.Equal(t, 37*time.Second, *tm)
+	assert.Equal(t, 3, *i)
+	assert.Equal(t, "hi", *s)
+	assert.Equal(t, 4, f.NFlag())
+	assert.Equal(t, 2, f.NArg())
+	assert.Equal(t, []string{"effie", "tom"}, f.Args())
+	assert.Equal(t, "effie", f.Arg(0))
+	assert.Equal(t, "", f.Arg(2))
+}
+
+func TestFlagSetParseDoesNotModifyArgs(t *testing.T) {
+	f, _ := newTestFlagSet()
+	f.Bool("n", false, "")
+	args := []string{"effie", "-n"}
+	assert.NoError(t, f.Parse(args))
+	assert.Equal(t, []string{"effie", "-n"}, args)
+	assert.Equal(t, []string{"effie"}, f.Args())
+}
+
+func TestFlagSetParseAbbreviated(t *testing.T) {
+	f, _ := newTestFlagSet()
+	s := f.String("name", "", "")
+	assert.NoError(t, f.Parse([]string{"--na", "effie"}))
+	assert.Equal(t, "effie", *s)
+}
+
+func TestFlagSetParseTerminator(t *testing.T) {
+	f, _ := newTestFlagSet()
+	n := f.Bool("n", false, "")
+	assert.NoError(t, f.Parse([]string{"--", "-n"}))
+	assert.False(t, *n)
+	assert.Equal(t, []string{"-n"}, f.Args())
+}
+
+func TestFlagSetParseEmpty(t *testing.T) {
+	f, _ := newTestFlagSet()
+	f.Bool("n", false, "")
+	assert.NoError(t, f.Parse([]string{}))
+	assert.Equal(t, 0, f.NArg())
+	assert.Equal(t, 0, f.NFlag())
+}
+
+func TestFlagSetParseErrors(t *testing.T) {
+	f, b := newTestFlagSet()
+	f.Int("i", 0, "")
+	err := f.Parse([]string{"-x"})
+	assert.EqualError(t, err, "flag provided but not defined: -x")
+	assert.Contains(t, b.String(), "Usage of test:")
+
+	f, _ = newTestFlagSet()
+	f.Int("i", 0, "")
+	err = f.Parse([]string{"--xist=3"})
+	assert.EqualError(t, err, "flag provided but not defined: --xist")
+
+	f, _ = newTestFlagSet()
+	f.Int("i", 0, "")
+	err = f.Parse([]string{"-i"})
+	assert.EqualError(t, err, "flag needs an argument: -i")
+
+	f, _ = newTestFlagSet()
+	f.String("name", "", "")
+	err = f.Parse([]string{"--name"})
+	assert.EqualError(t, err, "flag needs an argument: --name")
+
+	f, _ = newTestFlagSet()
+	f.StringP("name", "n", "", "")
+	err = f.Parse([]string{"-n", "effie", "--name"})
+	assert.EqualError(t, err, "flag needs an argument: --name")
+
+	f, _ = newTestFlagSet()
+	f.String("name", "", "")
+	err = f.Parse([]string{"effie", "--name"})
+	assert.EqualError(t, err, "flag needs an argument: --name")
+
+	f, _ = newTestFlagSet()
+	f.String("name", "", "")
+	err = f.Parse([]string{"--na"})
+	assert.EqualError(t, err, "flag needs an argument: --name")
+
+	f, _ = newTestFlagSet()
+	f.Int("i", 0, "")
+	err = f.Parse([]string{"-i", "abc"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid value "abc" for flag -i`)
+}
+
+func TestFlagSetParseHelp(t *testing.T) {
+	f, b := newTestFlagSet()
+	f.Bool("n", false, "")
+	assert.Equal(t, ErrHelp, f.Parse([]string{"-h"}))
+	assert.Contains(t, b.String(), "Usage of test:")
+
+	f, _ = newTestFlagSet()
+	assert.Equal(t, ErrHelp, f.Parse([]string{"--help"}))
+
+	f, _ = newTestFlagSet()
+	h := f.Bool("help", false, "")
+	assert.NoError(t, f.Parse([]string{"--help"}))
+	assert.True(t, *h)
+}
+
+func TestFlagSetParsePanicOnError(t *testing.T) {
+	f := NewFlagSet("test", PanicOnError)
+	f.SetOutput(&bytes.Buffer{})
+	assert.Panics(t, func() { f.Parse([]string{"-x"}) })
+}
+
+func TestFlagSetVisit(t *testing.T) {
+	f, _ := newTestFlagSet()
+	f.Bool("n", false, "")
+	f.String("time", "", "")
+	f.Int("a", 0, "")
+	assert.NoError(t, f.Parse([]string{"-n", "--time", "37"}))
+
+	all := []string{}
+	f.VisitAll(func(fl *Flag) { all = append(all, fl.Name) })
+	assert.Equal(t, []string{"a", "n", "time"}, all)
+
+	set := []string{}
+	f.Visit(func(fl *Flag) { set = append(set, fl.Name) })
+	assert.Equal(t, []string{"n", "time"}, set)
+}
+
+func TestFlagSetLookupAndSet(t *testing.T) {
+	f, _ := newTestFlagSet()
+	i := f.Int("i", 7, "")
+	fl := f.Lookup("i")
+	assert.NotNil(t, fl)
+	assert.Equal(t, "7", fl.DefValue)
+	assert.Nil(t, f.Lookup("x"))
+
+	assert.NoError(t, f.Set("i", "9"))
+	assert.Equal(t, 9, *i)
+	assert.Equal(t, 1, f.NFlag())
+	assert.Error(t, f.Set("x", "9"))
+}
+
+func TestFlagSetRedefined(t *testing.T) {
+	f, _ := newTestFlagSet()
+	f.Int("i", 0, "")
+	assert.Panics(t, func() { f.Int("i", 0, "") })
+}
+
+func TestPrintDefaults(t *testing.T) {
+	f, b := newTestFlagSet()
+	f.Bool("n", false, "name is a trailing arg")
+	f.Duration("time", 30*time.Second, "the `epoch`")
+	f.String("s", "x", "a string")
+	f.Int("count", 0, "a count")
+
+	exp := `  --count int
+    	a count
+  -n	name is a trailing arg
+  -s string
+    	a string (default "x")
+  --time epoch
+    	the epoch (default 30s)
+`
+	f.PrintDefaults()
+	assert.Equal(t, exp, b.String())
+}
@@ -0,0 +1,423 @@
+package flag
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NormalizedName is a flag name that has been normalized according to the
+// rules of a FlagSet's normalization function.
+type NormalizedName string
+
+// SetNormalizeFunc allows the addition of a function that translates flag
+// names. Flags added to the FlagSet are translated and then, when anything
+// tries to look up the flag, that name is translated as well. Long option
+// names on the command line are also translated before they are parsed.
+func (f *FlagSet) SetNormalizeFunc(
+	n func(f *FlagSet, name string) NormalizedName) {
+
+	f.normalizeNameFunc = n
+	for k, v := range f.formal {
+		nname := f.normalizeFlagName(v.Name)
+		if nname == k {
+			continue
+		}
+		v.Name = nname
+		delete(f.formal, k)
+		f.formal[nname] = v
+		if _, set := f.actual[k]; set {
+			delete(f.actual, k)
+			f.actual[nname] = v
+		}
+	}
+}
+
+// GetNormalizeFunc returns the previously set normalization function, or the
+// identity function if one was never set.
+func (f *FlagSet) GetNormalizeFunc() func(f *FlagSet, name string) NormalizedName {
+	if f.normalizeNameFunc != nil {
+		return f.normalizeNameFunc
+	}
+	return func(f *FlagSet, name string) NormalizedName {
+		return NormalizedName(name)
+	}
+}
+
+func (f *FlagSet) normalizeFlagName(name string) string {
+	return string(f.GetNormalizeFunc()(f, name))
+}
+
+// normalizeArgs translates the names of the long options in argv with the
+// flag set's normalization function. argv[0] and the elements following the
+// argument "--" are left untouched.
+func (f *FlagSet) normalizeArgs(argv []string) {
+	if f.normalizeNameFunc == nil {
+		return
+	}
+	for x := 1; x < len(argv); x++ {
+		a := argv[x]
+		if a == "--" {
+			return
+		}
+		if len(a) < 3 || !strings.HasPrefix(a, "--") {
+			continue
+		}
+		name, rest := a[2:], ""
+		if i := strings.IndexByte(name, '='); i > -1 {
+			name, rest = name[:i], name[i:]
+		}
+		argv[x] = "--" + f.normalizeFlagName(name) + rest
+	}
+}
+
+// Changed returns true if the flag was explicitly set during Parse() and
+// false otherwise.
+func (f *FlagSet) Changed(name string) bool {
+	fl := f.Lookup(name)
+	if fl == nil {
+		return false
+	}
+	return fl.Changed
+}
+
+// ShorthandLookup returns the Flag structure of the shorthand flag, returning
+// nil if none exists. It panics if len(name) > 1.
+func (f *FlagSet) ShorthandLookup(name string) *Flag {
+	if name == "" {
+		return nil
+	}
+	if len(name) > 1 {
+		f.panicf("can not look up shorthand which is more than one ASCII "+
+			"character: %q", name)
+	}
+	return f.shorthands[name[0]]
+}
+
+// MarkDeprecated indicates that a flag is deprecated in your program. It will
+// continue to function but will not show up in help or usage messages. Using
+// this flag will also print the given usageMessage.
+func (f *FlagSet) MarkDeprecated(name string, usageMessage string) error {
+	fl := f.Lookup(name)
+	if fl == nil {
+		return fmt.Errorf("flag %q does not exist", name)
+	}
+	if usageMessage == "" {
+		return fmt.Errorf("deprecated message for flag %q must be set", name)
+	}
+	fl.Deprecated = usageMessage
+	fl.Hidden = true
+	return nil
+}
+
+// MarkShorthandDeprecated will mark the shorthand of a flag deprecated in
+// your program. It will continue to function but will not show up in help or
+// usage messages. Using this flag will also print the given usageMessage.
+func (f *FlagSet) MarkShorthandDeprecated(
+	name string, usageMessage string) error {
+
+	fl := f.Lookup(name)
+	if fl == nil {
+		return fmt.Errorf("flag %q does not exist", name)
+	}
+	if usageMessage == "" {
+		return fmt.Errorf("deprecated message for flag %q must be set", name)
+	}
+	fl.ShorthandDeprecated = usageMessage
+	return nil
+}
+
+// MarkHidden sets a flag to 'hidden' in your program. It will continue to
+// function but will not show up in help or usage messages.
+func (f *FlagSet) MarkHidden(name string) error {
+	fl := f.Lookup(name)
+	if fl == nil {
+		return fmt.Errorf("flag %q does not exist", name)
+	}
+	fl.Hidden = true
+	return nil
+}
+
+// VarP is like Var, but accepts a shorthand letter that can be used after a
+// single dash.
+func (f *FlagSet) VarP(value Value, name, shorthand, usage string) {
+	f.VarPF(value, name, shorthand, usage)
+}
+
+// VarPF is like VarP, but returns the flag created.
+func (f *FlagSet) VarPF(value Value, name, shorthand, usage string) *Flag {
+	fl := &Flag{
+		Name:      name,
+		Shorthand: shorthand,
+		Usage:     usage,
+		Value:     value,
+		DefValue:  value.String(),
+	}
+	f.AddFlag(fl)
+	return fl
+}
+
+// BoolVarP is like BoolVar, but accepts a shorthand letter that can be used
+// after a single dash.
+func (f *FlagSet) BoolVarP(
+	p *bool, name, shorthand string, value bool, usage string) {
+
+	f.VarP(newBoolValue(value, p), name, shorthand, usage)
+}
+
+// BoolP is like Bool, but accepts a shorthand letter that can be used after a
+// single dash.
+func (f *FlagSet) BoolP(
+	name, shorthand string, value bool, usage string) *bool {
+
+	p := new(bool)
+	f.BoolVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// IntVarP is like IntVar, but accepts a shorthand letter that can be used
+// after a single dash.
+func (f *FlagSet) IntVarP(
+	p *int, name, shorthand string, value int, usage string) {
+
+	f.VarP(newIntValue(value, p), name, shorthand, usage)
+}
+
+// IntP is like Int, but accepts a shorthand letter that can be used after a
+// single dash.
+func (f *FlagSet) IntP(name, shorthand string, value int, usage string) *int {
+	p := new(int)
+	f.IntVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// Int64VarP is like Int64Var, but accepts a shorthand letter that can be used
+// after a single dash.
+func (f *FlagSet) Int64VarP(
+	p *int64, name, shorthand string, value int64, usage string) {
+
+	f.VarP(newInt64Value(value, p), name, shorthand, usage)
+}
+
+// Int64P is like Int64, but accepts a shorthand letter that can be used after
+// a single dash.
+func (f *FlagSet) Int64P(
+	name, shorthand string, value int64, usage string) *int64 {
+
+	p := new(int64)
+	f.Int64VarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// UintVarP is like UintVar, but accepts a shorthand letter that can be used
+// after a single dash.
+func (f *FlagSet) UintVarP(
+	p *uint, name, shorthand string, value uint, usage string) {
+
+	f.VarP(newUintValue(value, p), name, shorthand, usage)
+}
+
+// UintP is like Uint, but accepts a shorthand letter that can be used after a
+// single dash.
+func (f *FlagSet) UintP(
+	name, shorthand string, value uint, usage string) *uint {
+
+	p := new(uint)
+	f.UintVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// Uint64VarP is like Uint64Var, but accepts a shorthand letter that can be
+// used after a single dash.
+func (f *FlagSet) Uint64VarP(
+	p *uint64, name, shorthand string, value uint64, usage string) {
+
+	f.VarP(newUint64Value(value, p), name, shorthand, usage)
+}
+
+// Uint64P is like Uint64, but accepts a shorthand letter that can be used
+// after a single dash.
+func (f *FlagSet) Uint64P(
+	name, shorthand string, value uint64, usage string) *uint64 {
+
+	p := new(uint64)
+	f.Uint64VarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// StringVarP is like StringVar, but accepts a shorthand letter that can be
+// used after a single dash.
+func (f *FlagSet) StringVarP(
+	p *string, name, shorthand string, value string, usage string) {
+
+	f.VarP(newStringValue(value, p), name, shorthand, usage)
+}
+
+// StringP is like String, but accepts a shorthand letter that can be used
+// after a single dash.
+func (f *FlagSet) StringP(
+	name, shorthand string, value string, usage string) *string {
+
+	p := new(string)
+	f.StringVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// Float64VarP is like Float64Var, but accepts a shorthand letter that can be
+// used after a single dash.
+func (f *FlagSet) Float64VarP(
+	p *float64, name, shorthand string, value float64, usage string) {
+
+	f.VarP(newFloat64Value(value, p), name, shorthand, usage)
+}
+
+// Float64P is like Float64, but accepts a shorthand letter that can be used
+// after a single dash.
+func (f *FlagSet) Float64P(
+	name, shorthand string, value float64, usage string) *float64 {
+
+	p := new(float64)
+	f.Float64VarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// DurationVarP is like DurationVar, but accepts a shorthand letter that can
+// be used after a single dash.
+func (f *FlagSet) DurationVarP(
+	p *time.Duration,
+	name, shorthand string, value time.Duration, usage string) {
+
+	f.VarP(newDurationValue(value, p), name, shorthand, usage)
+}
+
+// DurationP is like Duration, but accepts a shorthand letter that can be used
+// after a single dash.
+func (f *FlagSet) DurationP(
+	name, shorthand string, value time.Duration, usage string) *time.Duration {
+
+	p := new(time.Duration)
+	f.DurationVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// SetNormalizeFunc sets the normalization function of the command-line flags.
+func SetNormalizeFunc(n func(f *FlagSet, name string) NormalizedName) {
+	CommandLine.SetNormalizeFunc(n)
+}
+
+// ShorthandLookup returns the Flag structure of the shorthand command-line
+// flag, returning nil if none exists.
+func ShorthandLookup(name string) *Flag {
+	return CommandLine.ShorthandLookup(name)
+}
+
+// VarP is like Var, but accepts a shorthand letter that can be used after a
+// single dash.
+func VarP(value Value, name, shorthand, usage string) {
+	CommandLine.VarP(value, name, shorthand, usage)
+}
+
+// BoolVarP is like BoolVar, but accepts a shorthand letter that can be used
+// after a single dash.
+func BoolVarP(p *bool, name, shorthand string, value bool, usage string) {
+	CommandLine.BoolVarP(p, name, shorthand, value, usage)
+}
+
+// BoolP is like Bool, but accepts a shorthand letter that can be used after a
+// single dash.
+func BoolP(name, shorthand string, value bool, usage string) *bool {
+	return CommandLine.BoolP(name, shorthand, value, usage)
+}
+
+// IntVarP is like IntVar, but accepts a shorthand letter that can be used
+// after a single dash.
+func IntVarP(p *int, name, shorthand string, value int, usage string) {
+	CommandLine.IntVarP(p, name, shorthand, value, usage)
+}
+
+// IntP is like Int, but accepts a shorthand letter that can be used after a
+// single dash.
+func IntP(name, shorthand string, value int, usage string) *int {
+	return CommandLine.IntP(name, shorthand, value, usage)
+}
+
+// Int64VarP is like Int64Var, but accepts a shorthand letter that can be used
+// after a single dash.
+func Int64VarP(p *int64, name, shorthand string, value int64, usage string) {
+	CommandLine.Int64VarP(p, name, shorthand, value, usage)
+}
+
+// Int64P is like Int64, but accepts a shorthand letter that can be used after
+// a single dash.
+func Int64P(name, shorthand string, value int64, usage string) *int64 {
+	return CommandLine.Int64P(name, shorthand, value, usage)
+}
+
+// UintVarP is like UintVar, but accepts a shorthand letter that can be used
+// after a single dash.
+func UintVarP(p *uint, name, shorthand string, value uint, usage string) {
+	CommandLine.UintVarP(p, name, shorthand, value, usage)
+}
+
+// UintP is like Uint, but accepts a shorthand letter that can be used after a
+// single dash.
+func UintP(name, shorthand string, value uint, usage string) *uint {
+	return CommandLine.UintP(name, shorthand, value, usage)
+}
+
+// Uint64VarP is like Uint64Var, but accepts a shorthand letter that can be
+// used after a single dash.
+func Uint64VarP(
+	p *uint64, name, shorthand string, value uint64, usage string) {
+
+	CommandLine.Uint64VarP(p, name, shorthand, value, usage)
+}
+
+// Uint64P is like Uint64, but accepts a shorthand letter that can be used
+// after a single dash.
+func Uint64P(name, shorthand string, value uint64, usage string) *uint64 {
+	return CommandLine.Uint64P(name, shorthand, value, usage)
+}
+
+// StringVarP is like StringVar, but accepts a shorthand letter that can be
+// used after a single dash.
+func StringVarP(p *string, name, shorthand string, value string, usage string) {
+	CommandLine.StringVarP(p, name, shorthand, value, usage)
+}
+
+// StringP is like String, but accepts a shorthand letter that can be used
+// after a single dash.
+func StringP(name, shorthand string, value string, usage string) *string {
+	return CommandLine.StringP(name, shorthand, value, usage)
+}
+
+// Float64VarP is like Float64Var, but accepts a shorthand letter that can be
+// used after a single dash.
+func Float64VarP(
+	p *float64, name, shorthand string, value float64, usage string) {
+
+	CommandLine.Float64VarP(p, name, shorthand, value, usage)
+}
+
+// Float64P is like Float64, but accepts a shorthand letter that can be used
+// after a single dash.
+func Float64P(name, shorthand string, value float64, usage string) *float64 {
+	return CommandLine.Float64P(name, shorthand, value, usage)
+}
+
+// DurationVarP is like DurationVar, but accepts a shorthand letter that can
+// be used after a single dash.
+func DurationVarP(
+	p *time.Duration,
+	name, shorthand string, value time.Duration, usage string) {
+
+	CommandLine.DurationVarP(p, name, shorthand, value, usage)
+}
+
+// DurationP is like Duration, but accepts a shorthand letter that can be used
+// after a single dash.
+func DurationP(
+	name, shorthand string, value time.Duration, usage string) *time.Duration {
+
+	return CommandLine.DurationP(name, shorthand, value, usage)
+}
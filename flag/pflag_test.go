@@ -0,0 +1,199 @@
+package flag
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlagSetParseShorthand(t *testing.T) {
+	f, _ := newTestFlagSet()
+	n := f.BoolP("name", "n", false, "")
+	tm := f.DurationP("time", "t", 0, "")
+	s := f.StringP("str", "s", "", "")
+
+	assert.NoError(t, f.Parse([]string{"-nt37s", "effie", "--str", "hi"}))
+	assert.True(t, *n)
+	assert.Equal(t, 37*time.Second, *tm)
+	assert.Equal(t, "hi", *s)
+	assert.Equal(t, []string{"effie"}, f.Args())
+
+	assert.True(t, f.Changed("name"))
+	assert.True(t, f.Changed("time"))
+	assert.True(t, f.Changed("str"))
+	assert.False(t, f.Changed("nope"))
+	assert.Equal(t, "time", f.ShorthandLookup("t").Name)
+}
+
+func TestFlagSetParseBoolLongArg(t *testing.T) {
+	f, _ := newTestFlagSet()
+	v := f.BoolP("verbose", "v", true, "")
+	x := f.BoolP("xist", "x", false, "")
+
+	assert.NoError(t, f.Parse([]string{"--verbose=false", "-x", "effie"}))
+	assert.False(t, *v)
+	assert.True(t, *x)
+	assert.Equal(t, []string{"effie"}, f.Args())
+
+	f, _ = newTestFlagSet()
+	v = f.BoolP("verbose", "v", false, "")
+	x = f.BoolP("xist", "x", false, "")
+	assert.NoError(t, f.Parse([]string{"-vx", "--verbose", "effie"}))
+	assert.True(t, *v)
+	assert.True(t, *x)
+	assert.Equal(t, []string{"effie"}, f.Args())
+}
+
+func TestFlagSetParseNoOptDefVal(t *testing.T) {
+	newFlagSet := func() (*FlagSet, *string) {
+		f, _ := newTestFlagSet()
+		s := f.StringP("color", "c", "never", "")
+		f.Lookup("color").NoOptDefVal = "always"
+		return f, s
+	}
+
+	f, s := newFlagSet()
+	assert.NoError(t, f.Parse([]string{"--color", "effie"}))
+	assert.Equal(t, "always", *s)
+	assert.Equal(t, []string{"effie"}, f.Args())
+
+	f, s = newFlagSet()
+	assert.NoError(t, f.Parse([]string{"--color=auto", "effie"}))
+	assert.Equal(t, "auto", *s)
+
+	f, s = newFlagSet()
+	assert.NoError(t, f.Parse([]string{"-c", "effie"}))
+	assert.Equal(t, "always", *s)
+
+	f, s = newFlagSet()
+	assert.NoError(t, f.Parse([]string{"-cauto", "effie"}))
+	assert.Equal(t, "auto", *s)
+
+	f, s = newFlagSet()
+	assert.NoError(t, f.Parse([]string{"effie"}))
+	assert.Equal(t, "never", *s)
+	assert.False(t, f.Changed("color"))
+}
+
+func TestFlagSetMarkDeprecated(t *testing.T) {
+	f, b := newTestFlagSet()
+	f.BoolP("old", "o", false, "the old way")
+	f.BoolP("new", "n", false, "the new way")
+	assert.NoError(t, f.MarkDeprecated("old", "use --new instead"))
+	assert.Error(t, f.MarkDeprecated("old", ""))
+	assert.Error(t, f.MarkDeprecated("nope", "msg"))
+
+	f.PrintDefaults()
+	assert.NotContains(t, b.String(), "--old")
+	assert.Contains(t, b.String(), "--new")
+
+	b.Reset()
+	assert.NoError(t, f.Parse([]string{"--old"}))
+	assert.True(t, f.Changed("old"))
+	assert.Equal(t,
+		"Flag --old has been deprecated, use --new instead\n", b.String())
+}
+
+func TestFlagSetMarkShorthandDeprecated(t *testing.T) {
+	f, b := newTestFlagSet()
+	f.BoolP("old", "o", false, "the old way")
+	assert.NoError(t, f.MarkShorthandDeprecated("old", "use --old instead"))
+
+	assert.NoError(t, f.Parse([]string{"--old"}))
+	assert.Equal(t, "", b.String())
+
+	assert.NoError(t, f.Parse([]string{"-o"}))
+	assert.Equal(t,
+		"Flag shorthand -o has been deprecated, use --old instead\n",
+		b.String())
+
+	f, b = newTestFlagSet()
+	s := f.StringP("name", "n", "", "the name")
+	assert.NoError(t, f.MarkShorthandDeprecated("name", "use --name instead"))
+
+	f.PrintDefaults()
+	assert.Contains(t, b.String(), "--name")
+	assert.NotContains(t, b.String(), "-n,")
+
+	b.Reset()
+	assert.NoError(t, f.Parse([]string{"--name", "effie"}))
+	assert.Equal(t, "", b.String())
+	assert.Equal(t, "effie", *s)
+
+	assert.NoError(t, f.Parse([]string{"-n", "play"}))
+	assert.Equal(t,
+		"Flag shorthand -n has been deprecated, use --name instead\n",
+		b.String())
+	assert.Equal(t, "play", *s)
+}
+
+func TestFlagSetMarkHidden(t *testing.T) {
+	f, b := newTestFlagSet()
+	s := f.String("secret", "", "a secret")
+	assert.NoError(t, f.MarkHidden("secret"))
+	f.PrintDefaults()
+	assert.Equal(t, "", b.String())
+
+	assert.NoError(t, f.Parse([]string{"--secret", "effie"}))
+	assert.Equal(t, "effie", *s)
+}
+
+func TestFlagSetSetNormalizeFunc(t *testing.T) {
+	f, _ := newTestFlagSet()
+	s := f.String("my_flag", "", "")
+	f.SetNormalizeFunc(func(f *FlagSet, name string) NormalizedName {
+		return NormalizedName(strings.Replace(name, "_", "-", -1))
+	})
+	assert.NotNil(t, f.Lookup("my-flag"))
+	assert.NotNil(t, f.Lookup("my_flag"))
+	assert.Equal(t, "my-flag", f.Lookup("my_flag").Name)
+
+	assert.NoError(t, f.Parse([]string{"--my_flag=effie"}))
+	assert.Equal(t, "effie", *s)
+	assert.True(t, f.Changed("my-flag"))
+
+	i := f.Int("other_flag", 0, "")
+	assert.NoError(t, f.Parse([]string{"--other-flag", "3"}))
+	assert.Equal(t, 3, *i)
+	assert.Equal(t, "other-flag", f.Lookup("other_flag").Name)
+}
+
+func TestFlagSetShorthandRedefined(t *testing.T) {
+	f, _ := newTestFlagSet()
+	f.BoolP("name", "n", false, "")
+	assert.Panics(t, func() { f.BoolP("nope", "n", false, "") })
+	assert.Panics(t, func() { f.BoolP("nope", "no", false, "") })
+	assert.Panics(t, func() { f.Bool("n", false, "") })
+}
+
+func TestPrintDefaultsShorthand(t *testing.T) {
+	f, b := newTestFlagSet()
+	f.BoolP("name", "n", false, "name is a trailing arg")
+	f.StringP("color", "c", "never", "when to use `color`")
+	f.Lookup("color").NoOptDefVal = "always"
+	f.IntP("level", "l", 3, "the level")
+
+	exp := `  -c, --color color[="always"]
+    	when to use color (default "never")
+  -l, --level int
+    	the level (default 3)
+  -n, --name
+    	name is a trailing arg
+`
+	f.PrintDefaults()
+	assert.Equal(t, exp, b.String())
+}
+
+func TestFlagSetVarP(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(&bytes.Buffer{})
+	var s string
+	fl := f.VarPF(newStringValue("def", &s), "str", "s", "a string")
+	assert.Equal(t, "def", fl.DefValue)
+	assert.Equal(t, fl, f.ShorthandLookup("s"))
+	assert.NoError(t, f.Parse([]string{"-seffie"}))
+	assert.Equal(t, "effie", s)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"unicode/utf8"
 )
 
 // orderTypes are requireOrder, permute, and returnInOrder
@@ -111,6 +112,9 @@ func NewGetOptParser() *GetOptParser {
 // If a letter in optString is followed by two colons, its argument is
 // optional.
 //
+// Option letters may be any Unicode characters, which are decoded from
+// optString and argv as UTF-8, ex. "λ:n" accepts -λ37 and -nλ 37.
+//
 // The argument '--' causes premature termination of argument
 // scanning, explicitly telling 'GetOpt' that there are no more
 // options.
@@ -195,7 +199,7 @@ func getOptInternalR(argc int, argv []string, optString string,
 	}
 
 	nonOptionP := func() bool {
-		return argv[d.optInd][0] != '-' || len(argv[d.optInd]) == 1
+		return len(argv[d.optInd]) < 2 || argv[d.optInd][0] != '-'
 	}
 
 	if d.nextChar == nil {
@@ -318,8 +322,8 @@ func getOptInternalR(argc int, argv []string, optString string,
 			optIndArg := argv[d.optInd]
 			lenOptIndArg := len(optIndArg)
 			if lenOptIndArg > 0 {
-				optIndArg2ndCharIsOpt = strings.IndexByte(
-					optString, optIndArg[1]) == -1
+				r, _ := utf8.DecodeRuneInString(optIndArg[1:])
+				optIndArg2ndCharIsOpt = strings.IndexRune(optString, r) == -1
 				if lenOptIndArg > 1 {
 					char1IsDash = optIndArg[1] == '-'
 					lenOptIndArgGt2 = lenOptIndArg > 2
@@ -356,7 +360,7 @@ func getOptInternalR(argc int, argv []string, optString string,
 		for optionIndex, p = range longOpts {
 
 			debugf("p.Name=%s", p.Name)
-			if strncmpb(p.Name, argv[d.optInd][*d.nextChar:], nameLen) {
+			if isLongOptPrefix(p.Name, argv[d.optInd][*d.nextChar:], nameLen) {
 
 				debugf(
 					"argv[d.optInd][*d.nextChar:]=%s",
@@ -477,6 +481,9 @@ func getOptInternalR(argc int, argv []string, optString string,
 					//*d.nextChar += len(argv[d.optInd][*d.nextChar:])
 					d.nextChar = nil
 					d.optOpt = pFound.Val
+					if longInd != nil {
+						*longInd = optionIndex
+					}
 					if optString[0] == ':' {
 						return ':'
 					}
@@ -505,9 +512,10 @@ func getOptInternalR(argc int, argv []string, optString string,
 		// option, then it's an error.
 		//
 		// otherwise interpret it as a short option.
+		r, _ := utf8.DecodeRuneInString(argv[d.optInd][*d.nextChar:])
 		if !longOnly ||
 			argv[d.optInd][1] == '-' ||
-			strings.IndexByte(optString, argv[d.optInd][*d.nextChar]) == -1 {
+			strings.IndexRune(optString, r) == -1 {
 
 			if printErrors {
 				if argv[d.optInd][1] == '-' {
@@ -522,7 +530,7 @@ func getOptInternalR(argc int, argv []string, optString string,
 						"%s: unrecognized option '%c%c'\n",
 						argv[0],
 						argv[d.optInd][0],
-						argv[d.optInd][*d.nextChar])
+						r)
 				}
 			}
 
@@ -542,19 +550,27 @@ func getOptInternalR(argc int, argv []string, optString string,
 		debugf("nextChar=nil, optInd=%d", d.optInd)
 	}
 
-	var c byte
+	// option characters are decoded as UTF-8 so that an option may be any
+	// rune, ex. -λ
+	var c rune
+	size := 1
 	if d.nextChar != nil && d.optInd < argc {
-		c = argv[d.optInd][*d.nextChar]
+		c, size = utf8.DecodeRuneInString(argv[d.optInd][*d.nextChar:])
 	}
-	*d.nextChar++
+	*d.nextChar += size
 
 	if d.optInd < argc && *d.nextChar >= len(argv[d.optInd]) {
 		d.nextChar = nil
 	}
 
-	var temp string
-	if tempInd := strings.IndexByte(optString, c); tempInd > -1 {
-		temp = optString[tempInd:]
+	// temp is the part of optString that follows the option character
+	var (
+		temp  string
+		found bool
+	)
+	if tempInd := strings.IndexRune(optString, c); tempInd > -1 {
+		temp = optString[tempInd+utf8.RuneLen(c):]
+		found = true
 	}
 
 	// increment `optind' when we start to process its last character
@@ -562,7 +578,7 @@ func getOptInternalR(argc int, argv []string, optString string,
 		d.optInd++
 	}
 
-	if temp == "" || c == ':' || c == ';' {
+	if !found || c == ':' || c == ';' || c == utf8.RuneError {
 		debugf("temp=%s, c=%[2]d|%[2]c", temp, c)
 		if printErrors {
 			fmt.Fprintf(
@@ -573,10 +589,8 @@ func getOptInternalR(argc int, argv []string, optString string,
 		return '?'
 	}
 
-	lenTemp := len(temp)
-
 	// convenience. Treat POSIX -W foo same as long option --foo
-	if lenTemp > 1 && temp[0] == 'W' && temp[1] == ';' {
+	if c == 'W' && strings.HasPrefix(temp, ";") {
 		debugln("found -W")
 		if longOpts == nil {
 			debugln("nolongs")
@@ -599,7 +613,7 @@ func getOptInternalR(argc int, argv []string, optString string,
 		// this is an option that requires an argument.
 		if d.nextChar != nil && *d.nextChar < len(argv[d.optInd]) {
 
-			debugf("option '-W %s' requires arg", c)
+			debugf("option '-W %c' requires arg", c)
 
 			d.optArg = argv[d.optInd][*d.nextChar:]
 			// if we end this ARGV-element by taking the rest as an arg,
@@ -643,7 +657,7 @@ func getOptInternalR(argc int, argv []string, optString string,
 		for optionIndex, p = range longOpts {
 
 			debugf("-W p.Name=%s", p.Name)
-			if strncmpb(p.Name, d.optArg[*d.nextChar:], nameLen) {
+			if isLongOptPrefix(p.Name, d.optArg[*d.nextChar:], nameLen) {
 
 				debugf("-W d.optArg=%s", d.optArg)
 
@@ -729,6 +743,9 @@ func getOptInternalR(argc int, argv []string, optString string,
 					}
 					//*d.nextChar += len(argv[d.optInd][*d.nextChar:])
 					d.nextChar = nil
+					if longInd != nil {
+						*longInd = optionIndex
+					}
 					if optString[0] == ':' {
 						return ':'
 					}
@@ -755,10 +772,10 @@ func getOptInternalR(argc int, argv []string, optString string,
 		return 'W'
 	}
 
-	debugf("temp=%v, len(temp)=%d", temp, lenTemp)
+	debugf("temp=%v, len(temp)=%d", temp, len(temp))
 
-	if lenTemp > 1 && temp[1] == ':' {
-		if lenTemp > 2 && temp[2] == ':' {
+	if strings.HasPrefix(temp, ":") {
+		if strings.HasPrefix(temp, "::") {
 			// this is an option that accepts an argument optionally
 			if d.nextChar != nil {
 				d.optArg = argv[d.optInd][*d.nextChar:]
@@ -41,7 +41,9 @@ type longOptList struct {
 }
 
 // GetOptLong behaves identically to GetOpt except long options beginning with
-// two dashes '--' are also accepted.
+// two dashes '--' are also accepted. The index of the long option that was
+// found is stored in longInd, including when the long option's required
+// argument is missing.
 func GetOptLong(
 	argv []string, optString string,
 	longOpts []*LongOption, longInd *int) int {
@@ -331,7 +331,7 @@ func testGetOptLongGlobal(t *testing.T, argv ...string) *getOptLongTestResult {
 			r.tfnd = true
 			r.nsecs = OptArg
 		case ':':
-			r.err = &ErrRequiredArg{OptOpt}
+			r.err = &ErrRequiredArg{Opt: OptOpt}
 			return r
 		case 'W':
 			r.err = &ErrUnknownOpt{OptOpt, OptArg}
@@ -402,7 +402,7 @@ func testGetOptLongInstance(t *testing.T, argv ...string) *getOptLongTestResult
 			r.tfnd = true
 			r.nsecs = p.OptArg
 		case ':':
-			r.err = &ErrRequiredArg{p.OptOpt}
+			r.err = &ErrRequiredArg{Opt: p.OptOpt}
 			return r
 		case 'W':
 			r.err = &ErrUnknownOpt{p.OptOpt, p.OptArg}
@@ -433,3 +433,34 @@ type getOptLongTestResult struct {
 	longOpt *LongOption
 	optArg  string
 }
+
+func TestGetOptLongUTF8Abbrev(t *testing.T) {
+	longOpts := []*LongOption{
+		{Name: "café", Type: NoArgument, Val: 'c'},
+		{Name: "cafè", Type: NoArgument, Val: 'C'},
+		{Name: "ab", Type: NoArgument, Val: 'a'},
+	}
+
+	parse := func(arg string) (int, int) {
+		p := NewGetOptParser()
+		p.OptErr = false
+		return p.GetOptLong([]string{"tglutf8", arg}, ":cCa", longOpts, nil),
+			p.OptOpt
+	}
+
+	// "caf" abbreviates two options
+	opt, _ := parse("--caf")
+	assert.Equal(t, '?', rune(opt))
+
+	opt, _ = parse("--café")
+	assert.Equal(t, 'c', rune(opt))
+
+	// the abbreviation may not end within a character; é and è share their
+	// first byte
+	opt, _ = parse("--caf\xc3")
+	assert.Equal(t, '?', rune(opt))
+
+	// an argument longer than the name does not match it
+	opt, _ = parse("--abc")
+	assert.Equal(t, '?', rune(opt))
+}
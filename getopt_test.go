@@ -201,7 +201,7 @@ func testGetOptInstance(t *testing.T, argv ...string) *getOptTestResult {
 			r.tfnd = true
 			r.nsecs = p.OptArg
 		case ':':
-			r.err = &ErrRequiredArg{p.OptOpt}
+			r.err = &ErrRequiredArg{Opt: p.OptOpt}
 			return r
 		default: // ?
 			r.err = &ErrUnknownOpt{p.OptOpt, ""}
@@ -243,7 +243,7 @@ func testGetOptGlobal(t *testing.T, argv ...string) *getOptTestResult {
 			r.tfnd = true
 			r.nsecs = OptArg
 		case ':':
-			r.err = &ErrRequiredArg{OptOpt}
+			r.err = &ErrRequiredArg{Opt: OptOpt}
 			return r
 		default: // ?
 			r.err = &ErrUnknownOpt{OptOpt, ""}
@@ -270,3 +270,18 @@ type getOptTestResult struct {
 	err    error
 	optInd int
 }
+
+func TestGetOptRunes(t *testing.T) {
+	p := NewGetOptParser()
+	p.OptErr = false
+	argv := []string{"tgrune01", "-λn", "-étemps", "-ü", "effie"}
+
+	assert.Equal(t, 'λ', rune(p.GetOpt(argv, ":λné:")))
+	assert.Equal(t, 'n', rune(p.GetOpt(argv, ":λné:")))
+	assert.Equal(t, 'é', rune(p.GetOpt(argv, ":λné:")))
+	assert.Equal(t, "temps", p.OptArg)
+	assert.Equal(t, '?', rune(p.GetOpt(argv, ":λné:")))
+	assert.Equal(t, 'ü', rune(p.OptOpt))
+	assert.Equal(t, -1, p.GetOpt(argv, ":λné:"))
+	assert.Equal(t, "effie", argv[p.OptInd])
+}
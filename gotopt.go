@@ -2,10 +2,14 @@ package gotopt
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 // Parser can be used to parse multiple argument slices.
@@ -14,11 +18,249 @@ type Parser interface {
 	// receives as they are parsed from the supplied arguments.
 	Parse(argv []string) (<-chan ParserState, error)
 
+	// ParseContext behaves identically to Parse except parsing stops when
+	// the context is canceled. A client that stops receiving before the
+	// channel is closed should cancel the context so the parse operation
+	// does not block forever on the channel. The channel is always closed
+	// once parsing stops, and the ParserStates received from a canceled
+	// parse operation should be considered incomplete.
+	ParseContext(ctx context.Context, argv []string) (<-chan ParserState, error)
+
+	// Iter returns an OptIterator that parses the options from the supplied
+	// arguments on the caller's goroutine as the iterator is advanced.
+	// Please see the documentation for the OptIterator type for more
+	// information.
+	Iter(argv []string) *OptIterator
+
+	// SetParseBuffer sets the capacity of the channels returned by Parse and
+	// ParseContext. The default capacity is zero, so each ParserState is
+	// parsed only after the previous one is received. A negative size is
+	// treated as zero.
+	SetParseBuffer(size int)
+
 	// ParseAll parses all arguments and then returns the final ParserState.
 	ParseAll(argv []string) (ParserState, error)
 
-	// Opt registers an option with the parser.
-	Opt(opt int, longName string, optType OptionTypes, argText, usage string)
+	// ParseResult parses all arguments like ParseAll and returns a
+	// ParseResult, which looks up the parsed options by name in constant
+	// time.
+	ParseResult(argv []string) (*ParseResult, error)
+
+	// Opt registers an option with the parser. The option's definition may be
+	// further modified by the provided OptModifiers.
+	Opt(
+		opt int, longName string, optType OptionTypes, argText, usage string,
+		mods ...OptModifier)
+
+	// Var registers an option with the parser and binds it to the provided
+	// Value. The Value's Set function is invoked with the option's argument
+	// each time the option is parsed. Options that do not take an argument
+	// are set with the string "true", or "false" when negated. If an
+	// option's optional argument is omitted, the Value is set with the string
+	// "true" if it has an IsBoolFlag function that returns true, and
+	// otherwise the Value is left unchanged.
+	//
+	// If Set returns an error then an ErrInvalidArg is sent in place of the
+	// Option.
+	Var(
+		value Value,
+		opt int, longName string, optType OptionTypes, argText, usage string,
+		mods ...OptModifier)
+
+	// BoolVar registers an option that does not take an argument and binds
+	// it to a bool variable. The variable is set to true when the option is
+	// parsed.
+	BoolVar(p *bool, opt int, longName, usage string, mods ...OptModifier)
+
+	// StringVar registers an option that requires an argument and binds it
+	// to a string variable.
+	StringVar(
+		p *string, opt int, longName, argText, usage string,
+		mods ...OptModifier)
+
+	// IntVar registers an option that requires an argument and binds it to
+	// an int variable.
+	IntVar(
+		p *int, opt int, longName, argText, usage string,
+		mods ...OptModifier)
+
+	// Int64Var registers an option that requires an argument and binds it to
+	// an int64 variable.
+	Int64Var(
+		p *int64, opt int, longName, argText, usage string,
+		mods ...OptModifier)
+
+	// UintVar registers an option that requires an argument and binds it to
+	// a uint variable.
+	UintVar(
+		p *uint, opt int, longName, argText, usage string,
+		mods ...OptModifier)
+
+	// Float64Var registers an option that requires an argument and binds it
+	// to a float64 variable.
+	Float64Var(
+		p *float64, opt int, longName, argText, usage string,
+		mods ...OptModifier)
+
+	// DurationVar registers an option that requires an argument and binds it
+	// to a time.Duration variable. The argument must be acceptable to
+	// time.ParseDuration.
+	DurationVar(
+		p *time.Duration, opt int, longName, argText, usage string,
+		mods ...OptModifier)
+
+	// CountVar registers a counting option and binds it to an int variable.
+	// The variable is incremented each time the option is given, ex. -vvv
+	// or --verbose --verbose, and is set to the level n when the option is
+	// given as --verbose=n. Please see the documentation for the Counter
+	// function for more information.
+	CountVar(p *int, opt int, longName, usage string, mods ...OptModifier)
+
+	// Bind registers an option for each of the exported fields of the struct
+	// to which v points and binds the options to the fields. Please see the
+	// documentation for the NewParserFor function for the supported tags.
+	Bind(v interface{}) error
+
+	// SetEnvPrefix enables environment variable fallback for every option
+	// with a long name. The name of an option's environment variable is the
+	// prefix followed by the option's long name in upper-case with dashes
+	// replaced by underscores, ex. the option --dry-run with the prefix
+	// "MYTOOL_" reads the variable MYTOOL_DRY_RUN. The prefix is inherited
+	// by the parser's commands unless they set their own.
+	//
+	// Please see the documentation for the Env function for how variables
+	// are read.
+	SetEnvPrefix(prefix string)
+
+	// LoadConfig reads option values from a configuration file. Files with
+	// the extension ".json" are read as JSON; all other files are read as
+	// INI-style files. Please see the documentation for the ConfigFormats
+	// type for a description of the formats.
+	//
+	// The options read from the file are sent after the options parsed from
+	// the argument list and the options read from the environment, and their
+	// Source is FromConfig. An option present in the argument list or the
+	// environment is not read from the file.
+	//
+	// Options must be registered before the file is loaded. An ErrConfigFile
+	// is returned if the file refers to an unknown option or gives an option
+	// a value that its OptionTypes does not allow.
+	LoadConfig(path string) error
+
+	// LoadConfigFrom behaves identically to LoadConfig except the
+	// configuration is read from the provided reader. The name is used in
+	// errors.
+	LoadConfigFrom(r io.Reader, name string, format ConfigFormats) error
+
+	// Command registers a command with the parser and returns the command's
+	// parser, with which the command's options and commands are registered.
+	//
+	// A parser with commands stops parsing options at the first non-option
+	// argument, which must be the name of a command. A Command is sent for
+	// the command, and then the command's parser parses the remaining
+	// arguments. An ErrUnknownCommand is sent if the argument is not the name
+	// of a command.
+	Command(name, usage string) Parser
+
+	// MutuallyExclusive declares that no more than one of the named options
+	// may be given. An option is named by its long name or option character,
+	// with or without the leading dashes, ex. "file", "--file", or "-f".
+	//
+	// The constraints are checked once the arguments are parsed, and an
+	// ErrConstraint is sent for each violated constraint. Options sent with
+	// their default values are not considered given.
+	MutuallyExclusive(names ...string)
+
+	// AtLeastOne declares that at least one of the named options must be
+	// given.
+	AtLeastOne(names ...string)
+
+	// ExactlyOne declares that exactly one of the named options must be
+	// given.
+	ExactlyOne(names ...string)
+
+	// Requires declares that if the named option is given, the other named
+	// options must be given as well.
+	Requires(name string, names ...string)
+
+	// ConflictsWith declares that if the named option is given, none of the
+	// other named options may be given.
+	ConflictsWith(name string, names ...string)
+
+	// Validate registers a function that is invoked once the arguments are
+	// parsed. The function receives the last ParserState of the list, and
+	// an error returned by the function is sent to the client as the value
+	// of a new ParserState.
+	//
+	// Validation functions are invoked after the ErrMissingOpt and
+	// ErrConstraint errors for any missing, required options and violated
+	// constraints are sent. The validation functions of
+	// a command's parser are invoked only if the command is parsed.
+	Validate(f ValidateFunc)
+
+	// Synopsis returns the usage synopsis, ex.
+	//
+	//     remote add [options] (--file | --url | --stdin)
+	//
+	// The synopsis begins with the names of the command and its ancestors,
+	// followed by the parser's constraints.
+	Synopsis() string
+
+	// GenerateCompletion writes a completion script for the shell to the
+	// provided stream. The supported shells are "bash", "zsh", and "fish".
+	//
+	// The script completes the options and commands of the parser and its
+	// commands, using the name of the root parser or the base name of the
+	// executable as the name of the program. Long options that take an
+	// argument complete with a trailing equals sign, and the choices of
+	// options restricted with the Choices modifier are offered as
+	// candidates for the options' arguments.
+	GenerateCompletion(shell string, w io.Writer) error
+
+	// CompleteArgs registers a function that returns the candidates for
+	// completing the parser's non-option arguments. The candidates for an
+	// option's argument are provided by the option's Completer modifier.
+	CompleteArgs(f CompleteFunc)
+
+	// Complete writes the candidates for completing the last argument of
+	// argv to the provided stream, one per line, if the first argument
+	// after the program name is CompleteCommand, ex.
+	//
+	//     prog __complete remote add --na
+	//
+	// The returned flag indicates whether or not argv was a completion
+	// request. Programs should call Complete before parsing their arguments
+	// and exit if the flag is true. The scripts written by GenerateCompletion
+	// use this protocol if any option or parser has a CompleteFunc.
+	Complete(argv []string, w io.Writer) (bool, error)
+
+	// Completions returns the candidates for completing the last of the
+	// words. The words are scanned with the same GetOpt loop as the
+	// arguments to Parse, so the candidates are those for the argument of
+	// the option that precedes the last word, ex. "-t" or "-nt", the
+	// argument inside of the last word, ex. "--time=3" or "-nt3", an option,
+	// a command, or a non-option argument.
+	Completions(words []string) []string
+
+	// WriteManPage writes a man(7) page to the provided stream. The page has
+	// the sections NAME, SYNOPSIS, DESCRIPTION, OPTIONS, COMMANDS,
+	// ENVIRONMENT, and EXIT STATUS, with the DESCRIPTION, COMMANDS, and
+	// ENVIRONMENT sections written only if there's something to put in them.
+	// The SYNOPSIS, OPTIONS, COMMANDS, and ENVIRONMENT sections are built
+	// from the parser's options and commands, and the rest from the
+	// provided metadata.
+	WriteManPage(w io.Writer, meta ManPageMeta) error
+
+	// WriteMarkdown writes Markdown reference documentation for the parser
+	// and its commands to the provided stream. Each parser is documented
+	// with its synopsis, a table of its options' short and long forms,
+	// arguments, defaults, and descriptions, and a table of its commands.
+	WriteMarkdown(w io.Writer) error
+
+	// WriteHTML writes HTML reference documentation for the parser and its
+	// commands to the provided stream. The documentation is the same as
+	// that written by WriteMarkdown, as an HTML fragment.
+	WriteHTML(w io.Writer) error
 
 	// Usage returns the usage text.
 	Usage() string
@@ -28,7 +270,29 @@ type Parser interface {
 
 	// PrintAndIndentUsage writes the usage text to the provided stream with
 	// each line indented by 'indent' number of white space characters.
+	//
+	// Descriptions are wrapped to the usage width, with continuation lines
+	// aligned with the first line of the description. A line break in a
+	// description begins a new line, and a blank line separates paragraphs.
+	// If an option or command is too wide for the description to fit next
+	// to it, the description begins on the following line.
+	//
+	// Options placed in a group with the Group modifier are listed under a
+	// heading with the group's name. The descriptions of all of the options
+	// and commands begin in the same column.
 	PrintAndIndentUsage(w io.Writer, indent int) error
+
+	// SetUsageWidth sets the width to which the usage text is wrapped. The
+	// width of a command's usage text is that of the nearest parser with a
+	// width. If no width is set, the width is the value of the environment
+	// variable COLUMNS, or 80 if COLUMNS is not a positive number.
+	SetUsageWidth(width int)
+
+	// SetUsageSort sets a flag indicating whether or not the usage text lists
+	// the options in each group sorted by their long names instead of in the
+	// order in which they were registered. The options of a command are
+	// sorted if the command or any of its ancestors sorts its options.
+	SetUsageSort(sorted bool)
 }
 
 // ParserState is the current state of the parser.
@@ -49,9 +313,10 @@ type Parser interface {
 // the navigation methods as soon as the ParseAll operation completes.
 type ParserState interface {
 	// Value returns the result of the interation of the GetOpt loop that this
-	// ParserState represents. The value can be an Option, an error, or if
-	// there are non-option arguments remaining during the final iteration of
-	// the GetOpt loop, an array of strings ([]string).
+	// ParserState represents. The value can be an Option, a Command, an
+	// error, or if there are non-option arguments remaining during the final
+	// iteration of the GetOpt loop, an array of strings ([]string). A
+	// DeprecationWarning precedes each Option that is deprecated.
 	Value() interface{}
 
 	// Index returns the index of the ParserState with respect to the total
@@ -127,13 +392,59 @@ type Option interface {
 	// are created, the first ParserState would have an index of 0, the second,
 	// 1, the third, 2, the fourth 3, and the fifth, 4.
 	Index() int
+
+	// Source returns a value indicating where the option was found. An
+	// option with a source of FromDefault was not given and has its default
+	// value.
+	Source() OptionSources
+
+	// Alias returns the alias of the option's long name with which the
+	// option was given; otherwise this function returns an empty string.
+	// LongName always returns the option's canonical long name.
+	Alias() string
+
+	// Count returns the number of times the option has been given up to and
+	// including this Option. The count of a counting option that is given a
+	// level, ex. --verbose=3, is the level plus the number of times the
+	// option has been given since.
+	Count() int
+
+	// Bool returns false if a negatable option was given in its negative
+	// form, ex. --no-color, if the count of a counting option is zero, or if
+	// the option's argument is false as parsed by strconv.ParseBool;
+	// otherwise this function returns true.
+	Bool() bool
 }
 
+// OptionSources are FromArgv, FromEnv, FromConfig, and FromDefault
+type OptionSources int
+
+const (
+	// FromArgv is for options parsed from the argument list
+	FromArgv OptionSources = iota
+
+	// FromEnv is for options read from environment variables
+	FromEnv
+
+	// FromConfig is for options read from configuration files
+	FromConfig
+
+	// FromDefault is for options that were not otherwise given and were
+	// sent with their default values
+	FromDefault
+)
+
 // parsedOpt is an option that's been parsed.
 type parsedOpt struct {
 	optDef
-	value string
-	index int
+	value     string
+	index     int
+	source    OptionSources
+	def       *optDef
+	argvIndex int
+	alias     string
+	negated   bool
+	count     int
 }
 
 func (o *parsedOpt) Opt() int {
@@ -151,6 +462,28 @@ func (o *parsedOpt) Value() string {
 func (o *parsedOpt) Index() int {
 	return o.index
 }
+func (o *parsedOpt) Source() OptionSources {
+	return o.source
+}
+func (o *parsedOpt) Alias() string {
+	return o.alias
+}
+func (o *parsedOpt) Count() int {
+	return o.count
+}
+func (o *parsedOpt) Bool() bool {
+	if o.negated {
+		return false
+	}
+	if o.def != nil && o.def.counter {
+		return o.count > 0
+	}
+	if o.optType == NoArgument {
+		return true
+	}
+	b, _ := strconv.ParseBool(o.value)
+	return b
+}
 func (o *parsedOpt) String() string {
 	b := &bytes.Buffer{}
 	b.WriteString("&{")
@@ -159,6 +492,7 @@ func (o *parsedOpt) String() string {
 	fmt.Fprintf(b, " Index:%d", o.index)
 	fmt.Fprintf(b, " Type:%+v", o.optType)
 	fmt.Fprintf(b, " Value:%s", o.value)
+	fmt.Fprintf(b, " Source:%d", o.source)
 	b.WriteString("}")
 	return b.String()
 }
@@ -231,44 +565,78 @@ func (p *parserState) LookupOptLong(opt string) []ParserState {
 				v = append(v, c)
 			}
 		}
-		c = p.next
+		c = c.next
 	}
 	return v
 }
 
 // parser is the backing struct for the Parser interface.
 type parser struct {
-	parsed      bool
-	opts        map[*optDef]*optDef
-	optsOrdered []*optDef
-	shortOpts   map[int]*optDef
-	longOpts    map[string]*optDef
-	maxUsageLen int
+	parsed         bool
+	opts           map[*optDef]*optDef
+	optsOrdered    []*optDef
+	shortOpts      map[int]*optDef
+	longOpts       map[string]*optDef
+	name           string
+	desc           string
+	parent         *parser
+	commands       []*parser
+	commandsByName map[string]*parser
+	maxCmdLen      int
+	envPrefix      string
+	config         []*configValue
+	validators     []ValidateFunc
+	constraints    []*constraint
+	argCompleter   CompleteFunc
+	width          int
+	sortUsage      bool
+	parseBuffer    int
 }
 
 // NewParser returns a new parser.
 func NewParser() Parser {
+	return newParser()
+}
+
+func newParser() *parser {
 	return &parser{
-		opts:        map[*optDef]*optDef{},
-		shortOpts:   map[int]*optDef{},
-		longOpts:    map[string]*optDef{},
-		optsOrdered: []*optDef{},
+		opts:           map[*optDef]*optDef{},
+		shortOpts:      map[int]*optDef{},
+		longOpts:       map[string]*optDef{},
+		optsOrdered:    []*optDef{},
+		commandsByName: map[string]*parser{},
 	}
 }
 
 // Parse parses the supplied arguments.
 func (p *parser) Parse(argv []string) (<-chan ParserState, error) {
+	return p.ParseContext(context.Background(), argv)
+}
+
+// ParseContext parses the supplied arguments until the context is canceled.
+func (p *parser) ParseContext(
+	ctx context.Context, argv []string) (<-chan ParserState, error) {
+
 	if len(argv) == 0 {
 		return nil, ErrEmptyArgList
 	}
-	c := make(chan ParserState)
+	c := make(chan ParserState, p.parseBuffer)
 	go func() {
-		p.parse(argv, c)
-		close(c)
+		defer close(c)
+		p.parse(ctx, argv, c)
 	}()
 	return c, nil
 }
 
+// SetParseBuffer sets the capacity of the channels returned by Parse and
+// ParseContext.
+func (p *parser) SetParseBuffer(size int) {
+	if size < 0 {
+		size = 0
+	}
+	p.parseBuffer = size
+}
+
 // ParseAll parses the supplied arguments and returns the final ParserState.
 func (p *parser) ParseAll(argv []string) (ParserState, error) {
 	c, err := p.Parse(argv)
@@ -282,57 +650,145 @@ func (p *parser) ParseAll(argv []string) (ParserState, error) {
 	return ps, nil
 }
 
-func (p *parser) parse(argv []string, c chan<- ParserState) {
+// stateList builds the list of ParserStates sent to the client of a Parse
+// operation. A single list spans the parsers of all the commands invoked
+// by the parsed arguments.
+type stateList struct {
+	ctx        context.Context
+	c          chan<- ParserState
+	prev       *parserState
+	ind        int
+	optIndices map[*optDef]int
+	counts     map[*optDef]int
+	parsers    []*parser
+	argvOffset int
+	args       []string
+}
 
-	b := &bytes.Buffer{}
-	longOpts := []*LongOption{}
-	b.WriteString(":W;")
+// send appends the ParserState to the list and sends it to the client. Once
+// the list's context is canceled, nothing more is sent.
+func (l *stateList) send(ps *parserState) {
+	if l.stopped() {
+		return
+	}
+	ps.index = l.ind
+	ps.next = nil
+	l.ind++
+	if l.prev == nil {
+		ps.first = ps
+	} else {
+		ps.prev = l.prev
+		l.prev.next = ps
+		ps.first = l.prev.first
+	}
+	l.prev = ps
+	select {
+	case l.c <- ps:
+	case <-l.ctx.Done():
+	}
+}
 
-	for _, o := range p.opts {
-		debugf("opt.Opt=%[1]d|%[1]c, opt.LongName=%s", o.opt, o.longName)
+// stopped returns a flag indicating whether or not the list's context has
+// been canceled.
+func (l *stateList) stopped() bool {
+	return l.ctx.Err() != nil
+}
 
-		if o.opt > 0 {
-			b.WriteByte(byte(o.opt))
-			if o.optType == RequiredArgument || o.optType == OptionalArgument {
-				b.WriteByte(':')
-				if o.optType == OptionalArgument {
-					b.WriteByte(':')
-				}
-			}
-		}
-		if o.longName != "" {
-			lo := &LongOption{Name: o.longName, Type: o.optType}
-			if o.opt > 0 {
-				lo.Val = o.opt
-				lo.Flag = nil
-			}
-			longOpts = append(longOpts, lo)
-		}
+// sendOpt sends the ParserState of a parsed option to the list after
+// recording the option's count. If the option is deprecated and was given, a
+// DeprecationWarning is sent first.
+func (l *stateList) sendOpt(ps *parserState) {
+	po, ok := ps.value.(*parsedOpt)
+	if !ok {
+		l.send(ps)
+		return
 	}
 
-	longInd := 0
-	optString := b.String()
-	gop := NewGetOptParser()
-	var pf func() int
-	if len(longOpts) > 0 {
-		pf = func() int {
-			return gop.GetOptLong(argv, optString, longOpts, &longInd)
-		}
+	po.setCount(l.counts)
+
+	if po.def.deprecated && po.source != FromDefault {
+		l.send(&parserState{value: &DeprecationWarning{
+			po.opt, po.longName, po.def.replacement,
+		}})
+	}
+	l.send(ps)
+}
+
+// setCount records the option in counts, which maps options to the number of
+// times they've been given, and sets the option's count.
+func (o *parsedOpt) setCount(counts map[*optDef]int) {
+	if o.def.counter && o.value != "" {
+		counts[o.def], _ = strconv.Atoi(o.value)
 	} else {
-		pf = func() int {
-			return gop.GetOpt(argv, optString)
+		counts[o.def]++
+	}
+	o.count = counts[o.def]
+}
+
+// done sets the last node of the list on every node in the list.
+func (l *stateList) done() {
+	if l.prev == nil {
+		return
+	}
+	ps := l.prev.first
+	for {
+		ps.last = l.prev
+		ps = ps.next
+		if ps == nil {
+			break
 		}
 	}
+}
 
-	var (
-		psPrev *parserState
-		psInd  int
-	)
+func (p *parser) parse(
+	ctx context.Context, argv []string, c chan<- ParserState) {
 
-	optIndices := map[*optDef]int{}
+	l := &stateList{
+		ctx:        ctx,
+		c:          c,
+		optIndices: map[*optDef]int{},
+		counts:     map[*optDef]int{},
+	}
+	p.parseArgs(argv, l)
+	if !l.stopped() {
+		l.parseFallbacks()
+	}
+	if l.args != nil {
+		l.send(&parserState{value: l.args})
+	}
+	if !l.stopped() {
+		l.validate()
+	}
+	l.done()
+}
 
-	for {
-		opt := pf()
+// parseFallbacks sends the options of the parsers that parsed the argument
+// list that were not parsed from it but are read from the environment or
+// configuration files or have default values. The options are sent once all
+// of the parsers are done so that a persistent option given after the name
+// of a command is not also sent from one of those sources, and before the
+// non-option arguments that follow the options.
+func (l *stateList) parseFallbacks() {
+	for _, p := range l.parsers {
+		p.parseEnv(l)
+		p.parseConfig(l)
+		p.parseDefaults(l)
+	}
+}
+
+// parseArgs parses the arguments and sends the resulting ParserStates to the
+// list. If the parser has commands, option parsing stops at the first
+// non-option argument, which must be the name of one of the commands. The
+// arguments beginning with the command's name are then parsed by the
+// command's parser.
+func (p *parser) parseArgs(argv []string, l *stateList) {
+	l.parsers = append(l.parsers, p)
+
+	sc := p.newOptScanner(argv)
+	gop := sc.gop
+
+	for !l.stopped() {
+		opt, o := sc.next()
 		if opt == -1 {
 			break
 		}
@@ -343,119 +799,391 @@ func (p *parser) parse(argv []string, c chan<- ParserState) {
 
 		var psCurr *parserState
 
-		switch opt {
-		case 0:
-			if longInd > -1 && longInd < len(longOpts) {
-				if o, ok := p.longOpts[longOpts[longInd].Name]; ok {
-					optIdx, optIdxOk := optIndices[o]
-					if optIdxOk {
-						optIdx++
-					} else {
-						optIdx = 0
-					}
-					optIndices[o] = optIdx
-					psCurr = &parserState{
-						value: &parsedOpt{
-							optDef: optDef{
-								opt:      o.opt,
-								longName: o.longName,
-							},
-							value: gop.OptArg,
-							index: optIdx,
-						},
-					}
-				}
-			}
-		case ':':
-			psCurr = &parserState{
-				value: &ErrRequiredArg{gop.OptOpt},
-			}
-		case '?':
+		switch {
+		case o != nil:
+			psCurr = newParsedOptState(
+				o, gop.OptArg, sc.negated, FromArgv, l.optIndices)
+		case opt == 0:
+			// a long option that sets a flag
+		case opt == ':':
+			psCurr = &parserState{value: sc.missingArg()}
+		default:
 			psCurr = &parserState{
 				value: &ErrUnknownOpt{gop.OptOpt, gop.OptArg},
 			}
-		case 'W':
-			psCurr = &parserState{
-				value: &ErrUnknownOpt{gop.OptOpt, gop.OptArg},
+		}
+
+		if psCurr != nil {
+			if po, ok := psCurr.value.(*parsedOpt); ok {
+				po.argvIndex = l.argvOffset + optArgvIndex(argv, gop, po.def)
+				po.alias = sc.alias
 			}
-		default:
-			if o, ok := p.shortOpts[opt]; ok {
-				optIdx, optIdxOk := optIndices[o]
-				if optIdxOk {
-					optIdx++
-				} else {
-					optIdx = 0
-				}
-				optIndices[o] = optIdx
-				psCurr = &parserState{
-					value: &parsedOpt{
-						optDef: optDef{
-							opt:      o.opt,
-							longName: o.longName,
-						},
-						value: gop.OptArg,
-						index: optIdx,
-					},
-				}
-			} else {
-				psCurr = &parserState{
-					value: &ErrUnknownOpt{gop.OptOpt, gop.OptArg},
+			l.sendOpt(psCurr)
+		}
+	}
+
+	if l.stopped() {
+		return
+	}
+
+	if gop.OptInd >= len(argv) {
+		return
+	}
+
+	if len(p.commands) == 0 {
+		l.args = argv[gop.OptInd:]
+		return
+	}
+
+	name := argv[gop.OptInd]
+	cmd, ok := p.commandsByName[name]
+	if !ok {
+		l.send(&parserState{value: &ErrUnknownCommand{name}})
+		return
+	}
+
+	l.send(&parserState{value: &parsedCmd{cmd}})
+	l.argvOffset += gop.OptInd
+	cmd.parseArgs(argv[gop.OptInd:], l)
+}
+
+// optArgvIndex returns the index of the argument from which the option
+// just returned by the GetOpt parser was parsed.
+func optArgvIndex(argv []string, gop *GetOptParser, o *optDef) int {
+	// the parser is still scanning the option characters of an argument
+	if gop.data.nextChar != nil {
+		return gop.OptInd
+	}
+	// a required argument may be the argument after the option
+	if o.optType == RequiredArgument &&
+		gop.OptInd > 1 && argv[gop.OptInd-1] == gop.OptArg {
+		return gop.OptInd - 2
+	}
+	return gop.OptInd - 1
+}
+
+// optScanner scans the options of a parser from an argument list with a
+// GetOptParser.
+type optScanner struct {
+	gop         *GetOptParser
+	argv        []string
+	optString   string
+	longOpts    []*LongOption
+	longInd     int
+	shortOpts   map[int]*optDef
+	longOptDefs map[string]*optDef
+	negOptDefs  map[string]*optDef
+	alias       string
+	negated     bool
+}
+
+// newOptScanner returns a new optScanner for the options accepted by the
+// parser. If the parser has commands, scanning stops at the first non-option
+// argument.
+func (p *parser) newOptScanner(argv []string) *optScanner {
+	sc := &optScanner{
+		gop:         NewGetOptParser(),
+		argv:        argv,
+		shortOpts:   map[int]*optDef{},
+		longOptDefs: map[string]*optDef{},
+		negOptDefs:  map[string]*optDef{},
+	}
+
+	b := &bytes.Buffer{}
+	if len(p.commands) > 0 {
+		b.WriteByte('+')
+	}
+	b.WriteString(":W;")
+
+	for _, o := range p.allOpts() {
+		if !o.enabled() {
+			continue
+		}
+
+		debugf("opt.Opt=%[1]d|%[1]c, opt.LongName=%s", o.opt, o.longName)
+
+		if o.opt > 0 {
+			sc.shortOpts[o.opt] = o
+			b.WriteRune(rune(o.opt))
+
+			// the option character of a counting option does not take an
+			// argument so that it may be repeated, ex. -vvv
+			if !o.counter &&
+				(o.optType == RequiredArgument || o.optType == OptionalArgument) {
+				b.WriteByte(':')
+				if o.optType == OptionalArgument {
+					b.WriteByte(':')
 				}
 			}
 		}
 
-		if psCurr != nil {
-			psCurr.index = psInd
-			psCurr.next = nil
-			psInd++
-			if psPrev == nil {
-				psCurr.first = psCurr
-			} else {
-				psCurr.prev = psPrev
-				psPrev.next = psCurr
-				psCurr.first = psPrev.first
+		// the long names of an option share the option's value or flag so
+		// that an abbreviation of more than one of them is not ambiguous.
+		// the flag of an option without an option character identifies the
+		// option.
+		var flag *int
+		if o.opt == 0 {
+			flag = new(int)
+		}
+		for _, name := range o.longNames() {
+			sc.longOptDefs[name] = o
+			lo := &LongOption{Name: name, Type: o.optType, Flag: flag}
+			if o.opt > 0 {
+				lo.Val = o.opt
 			}
-			psPrev = psCurr
-			c <- psCurr
+			sc.longOpts = append(sc.longOpts, lo)
 		}
-	}
 
-	if gop.OptInd < len(argv) {
-		psCurr := &parserState{
-			index: psInd,
-			value: argv[gop.OptInd:],
+		// the negative forms of an option share a flag of their own so that
+		// an abbreviation that matches both the option and its negative form
+		// is ambiguous.
+		if o.negatable && o.optType == NoArgument {
+			negFlag := new(int)
+			for _, name := range o.longNames() {
+				sc.negOptDefs["no-"+name] = o
+				sc.longOpts = append(sc.longOpts, &LongOption{
+					Name: "no-" + name, Type: NoArgument, Flag: negFlag,
+				})
+			}
 		}
-		if psPrev == nil {
-			psCurr.first = psCurr
+	}
+
+	sc.optString = b.String()
+	return sc
+}
+
+// missingArg returns the error for the option just returned by the GetOpt
+// parser whose required argument is missing.
+func (sc *optScanner) missingArg() *ErrRequiredArg {
+	e := &ErrRequiredArg{Opt: sc.gop.OptOpt}
+	if sc.longInd > -1 && sc.longInd < len(sc.longOpts) {
+		e.LongName = sc.longOpts[sc.longInd].Name
+	}
+	return e
+}
+
+// next returns the result of the next iteration of the GetOpt loop along with
+// the definition of the option that was found, if any. The result is -1 once
+// there are no more options. If the option was given by one of its aliases,
+// the alias is recorded in the scanner's alias field, and if the option was
+// given in its negative form, the scanner's negated field is set.
+func (sc *optScanner) next() (int, *optDef) {
+	var opt int
+	sc.alias = ""
+	sc.negated = false
+	sc.longInd = -1
+	if len(sc.longOpts) > 0 {
+		opt = sc.gop.GetOptLong(sc.argv, sc.optString, sc.longOpts, &sc.longInd)
+	} else {
+		opt = sc.gop.GetOpt(sc.argv, sc.optString)
+	}
+
+	switch opt {
+	case -1, ':', '?', 'W':
+		return opt, nil
+	}
+
+	var o *optDef
+	if sc.longInd > -1 && sc.longInd < len(sc.longOpts) {
+		name := sc.longOpts[sc.longInd].Name
+		if o = sc.negOptDefs[name]; o != nil {
+			sc.negated = true
+			name = strings.TrimPrefix(name, "no-")
 		} else {
-			psCurr.prev = psPrev
-			psPrev.next = psCurr
-			psCurr.first = psPrev.first
+			o = sc.longOptDefs[name]
+		}
+		if o != nil && name != o.longName {
+			sc.alias = name
 		}
-		psPrev = psCurr
-		c <- psCurr
+	} else if opt != 0 {
+		o = sc.shortOpts[opt]
 	}
+	return opt, o
+}
 
-	if psPrev != nil {
-		psCurr := psPrev.first
-		for {
-			psCurr.last = psPrev
-			psCurr = psCurr.next
-			if psCurr == nil {
-				break
+// newParsedOptState returns a new ParserState for a parsed option.
+//
+// The option's argument is first checked against the option's choices, range,
+// and regular expression, and the returned ParserState's value is an
+// ErrInvalidChoice or ErrInvalidArg if the check fails. If the option is bound
+// to a Value, the Value is set with the option's argument. The returned
+// ParserState's value is an ErrInvalidArg if the Value rejects the argument.
+// A negated option's Value is set with the string "false". If an option's
+// optional argument is omitted, a bool Value is set with the string "true",
+// and any other Value is left unchanged unless the option is a counter.
+func newParsedOptState(
+	o *optDef,
+	arg string,
+	negated bool,
+	source OptionSources,
+	optIndices map[*optDef]int) *parserState {
+
+	arg, err := o.checkArg(arg)
+	if err != nil {
+		return &parserState{value: err}
+	}
+
+	if o.value != nil {
+		val, set := arg, true
+		switch {
+		case o.optType == NoArgument:
+			val = strconv.FormatBool(!negated)
+		case o.optType == OptionalArgument && arg == "" && !o.counter:
+			bf, ok := o.value.(boolFlag)
+			val, set = "true", ok && bf.IsBoolFlag()
+		}
+		if set {
+			if err := o.value.Set(val); err != nil {
+				return &parserState{
+					value: &ErrInvalidArg{o.opt, o.longName, val, err},
+				}
 			}
 		}
 	}
+
+	optIdx, optIdxOk := optIndices[o]
+	if optIdxOk {
+		optIdx++
+	} else {
+		optIdx = 0
+	}
+	optIndices[o] = optIdx
+
+	return &parserState{
+		value: &parsedOpt{
+			optDef: optDef{
+				opt:      o.opt,
+				longName: o.longName,
+				optType:  o.optType,
+			},
+			value:     arg,
+			index:     optIdx,
+			source:    source,
+			def:       o,
+			argvIndex: -1,
+			negated:   negated,
+		},
+	}
 }
 
 // optDef is the definition of an option as recorded when registering options.
 type optDef struct {
-	opt      int
-	longName string
-	optType  OptionTypes
-	argText  string
-	desc     string
-	usageLen int
+	opt          int
+	longName     string
+	optType      OptionTypes
+	argText      string
+	desc         string
+	value        Value
+	defValue     string
+	hasDefault   bool
+	required     bool
+	persistent   bool
+	env          string
+	choices      []string
+	rangeMin     float64
+	rangeMax     float64
+	hasRange     bool
+	rx           *regexp.Regexp
+	completer    CompleteFunc
+	aliases      []string
+	negatable    bool
+	counter      bool
+	group        string
+	hidden       bool
+	deprecated   bool
+	replacement  string
+	experimental string
+}
+
+// longNames returns the option's long name followed by its aliases.
+func (o *optDef) longNames() []string {
+	if o.longName == "" {
+		return nil
+	}
+	return append([]string{o.longName}, o.aliases...)
+}
+
+// longForm returns the long name or alias as shown in the usage text, ex.
+// "--color", or "--[no-]color" if the option is negatable.
+func (o *optDef) longForm(name string) string {
+	if o.negatable {
+		return "--[no-]" + name
+	}
+	return "--" + name
+}
+
+// usageTerm returns the option as shown in the usage text, ex.
+// "-t, --time epoch". If hasOpt is true, options without an option character
+// are indented by four spaces to align their long names with those of the
+// options that have one.
+func (o *optDef) usageTerm(hasOpt bool) string {
+	b := &bytes.Buffer{}
+	if o.opt > 0 {
+		fmt.Fprintf(b, "-%c", o.opt)
+		if o.longName != "" {
+			b.WriteString(", ")
+		}
+	} else if hasOpt {
+		b.WriteString("    ")
+	}
+	for x, name := range o.longNames() {
+		if x > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(o.longForm(name))
+	}
+	switch {
+	case o.counter:
+		// the option character of a counter never takes an argument
+		if o.longName != "" {
+			fmt.Fprintf(b, "[=%s]", o.argName())
+		}
+	case o.optType != NoArgument:
+		b.WriteByte(' ')
+		b.WriteString(o.argText)
+	}
+	return b.String()
+}
+
+// description returns the option's description followed by its choices or
+// range, if either is known.
+func (o *optDef) description() string {
+	parts := []string{}
+	if o.desc != "" {
+		parts = append(parts, o.desc)
+	}
+	if len(o.choices) > 0 {
+		parts = append(parts,
+			fmt.Sprintf("(choices: %s)", strings.Join(o.choices, ", ")))
+	}
+	if o.hasRange {
+		parts = append(parts, fmt.Sprintf("(range: %s)", o.rangeText()))
+	}
+	if o.deprecated {
+		if o.replacement != "" {
+			parts = append(parts,
+				fmt.Sprintf("(deprecated: use %s)", o.replacement))
+		} else {
+			parts = append(parts, "(deprecated)")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// usage returns the option's description followed by its choices or range,
+// default value, and environment variable, if any are known.
+func (o *optDef) usage(envVar string) string {
+	parts := []string{}
+	if desc := o.description(); desc != "" {
+		parts = append(parts, desc)
+	}
+	if o.defValue != "" {
+		parts = append(parts, fmt.Sprintf("(default: %s)", o.defValue))
+	}
+	if envVar != "" {
+		parts = append(parts, fmt.Sprintf("[$%s]", envVar))
+	}
+	return strings.Join(parts, " ")
 }
 
 var optionalArgRx = regexp.MustCompile(`^[\[].+[\]]$`)
@@ -465,7 +1193,18 @@ func (p *parser) Opt(
 	opt int,
 	longName string,
 	optType OptionTypes,
-	argText, usage string) {
+	argText, usage string,
+	mods ...OptModifier) {
+
+	p.opt(opt, longName, optType, argText, usage, mods...)
+}
+
+func (p *parser) opt(
+	opt int,
+	longName string,
+	optType OptionTypes,
+	argText, usage string,
+	mods ...OptModifier) *optDef {
 
 	if opt <= 0 && longName == "" {
 		panic("opt and longName invalid")
@@ -487,41 +1226,39 @@ func (p *parser) Opt(
 		argText:  argText,
 	}
 
-	lln := len(o.longName)
-
-	if o.opt > 0 {
-		p.shortOpts[o.opt] = o
+	for _, m := range mods {
+		m(o)
 	}
 
-	if lln > 0 {
-		p.longOpts[o.longName] = o
+	if o.longName == "" && len(o.aliases) > 0 {
+		panic("aliases require longName")
 	}
 
-	if o.opt > 0 {
-		o.usageLen += 2
-		if lln > 0 {
-			o.usageLen += 2
-		} else if o.optType != NoArgument {
-			o.usageLen++
-		}
+	if o.negatable && (o.longName == "" || o.optType != NoArgument) {
+		panic("negatable option requires longName and NoArgument")
 	}
 
-	if lln > 0 {
-		o.usageLen += lln + 2
-		if o.optType != NoArgument {
-			o.usageLen++
+	if o.counter {
+		o.optType = OptionalArgument
+		if o.argText == "" {
+			o.argText = "n"
+		}
+		if !optionalArgRx.MatchString(o.argText) {
+			o.argText = fmt.Sprintf("[%s]", o.argText)
 		}
 	}
 
-	if o.optType != NoArgument {
-		o.usageLen += len(argText)
+	if o.opt > 0 {
+		p.shortOpts[o.opt] = o
 	}
 
-	if o.usageLen > p.maxUsageLen {
-		p.maxUsageLen = o.usageLen
+	for _, name := range o.longNames() {
+		p.longOpts[name] = o
 	}
+
 	p.opts[o] = o
 	p.optsOrdered = append(p.optsOrdered, o)
+	return o
 }
 
 func (p *parser) Usage() string {
@@ -543,77 +1280,127 @@ func (p *parser) PrintAndIndentUsage(w io.Writer, indent int) error {
 	//     -x, --xist [arg] The xist description.
 	//         --pulp       The pulp description.
 
-	hasOpt := len(p.shortOpts) > 0
-	indentStr := []byte(strings.Repeat(" ", indent))
-	maxUsage := p.maxUsageLen + indent + 1
+	opts := p.visibleOpts()
 
-	for _, o := range p.optsOrdered {
+	hasOpt := false
+	for _, o := range opts {
+		if o.opt > 0 {
+			hasOpt = true
+			break
+		}
+	}
 
-		var (
-			n, nn int
-			err   error
-		)
+	// options without an option character are indented by four spaces
+	// if any of the options have an option character. commands are indented
+	// by two spaces.
+	terms := make([]string, len(opts))
+	for x, o := range opts {
+		terms[x] = o.usageTerm(hasOpt)
+	}
+	cmdTerms := make([]string, len(p.commands))
+	for x, c := range p.commands {
+		cmdTerms[x] = "  " + c.name
+	}
 
-		// write the indent
-		if n, err = w.Write(indentStr); err != nil {
-			return err
+	// the descriptions begin in the column after the widest option or
+	// command. if that column is past the middle of the line, the column
+	// after the widest option or command that fits in the first half of the
+	// line is used instead, and the descriptions of the options and commands
+	// that do not fit begin on the next line.
+	width := p.usageWidth()
+	maxUsageLen := p.maxCmdLen + 2
+	for _, t := range terms {
+		if n := utf8.RuneCountInString(t); n > maxUsageLen {
+			maxUsageLen = n
 		}
-		nn += n
+	}
+	if indent+maxUsageLen+1 > width/2 {
+		maxUsageLen = 0
+		for _, t := range append(append([]string{}, terms...), cmdTerms...) {
+			n := utf8.RuneCountInString(t)
+			if indent+n+1 <= width/2 && n > maxUsageLen {
+				maxUsageLen = n
+			}
+		}
+	}
 
-		if o.opt > 0 {
-			if n, err = fmt.Fprintf(w, "-%c", o.opt); err != nil {
+	indentStr := strings.Repeat(" ", indent)
+	maxUsage := maxUsageLen + indent + 1
+
+	descWidth := width - maxUsage
+	if descWidth < minUsageDescWidth {
+		descWidth = minUsageDescWidth
+	}
+
+	// writeEntry writes the option or command followed by its wrapped
+	// description. continuation lines are aligned with the description.
+	writeEntry := func(term, desc string) error {
+		lines := wrapText(desc, descWidth)
+		termLen := utf8.RuneCountInString(term)
+		if termLen > maxUsageLen {
+			if _, err := fmt.Fprintf(w, "%s%s\n", indentStr, term); err != nil {
 				return err
 			}
-			nn += n
-		} else if hasOpt {
-			if n, err = fmt.Fprintf(w, "  "); err != nil {
+			if desc == "" {
+				return nil
+			}
+		} else {
+			ws := strings.Repeat(" ", maxUsage-indent-termLen)
+			if _, err := fmt.Fprintf(
+				w, "%s%s%s%s\n", indentStr, term, ws, lines[0]); err != nil {
 				return err
 			}
-			nn += n
+			lines = lines[1:]
 		}
-
-		if o.opt > 0 {
-			if o.longName != "" {
-				if n, err = fmt.Fprintf(w, ", "); err != nil {
-					return err
-				}
-				nn += n
+		for _, line := range lines {
+			if line != "" {
+				line = strings.Repeat(" ", maxUsage) + line
 			}
-		} else if hasOpt {
-			if n, err = fmt.Fprintf(w, "  "); err != nil {
+			if _, err := fmt.Fprintln(w, line); err != nil {
 				return err
 			}
-			nn += n
 		}
+		return nil
+	}
 
-		if o.longName != "" {
-			if n, err = fmt.Fprintf(w, "--%s", o.longName); err != nil {
-				return err
+	for x, g := range p.usageGroups(opts) {
+		if g.name != "" {
+			if x > 0 {
+				if _, err := fmt.Fprintln(w); err != nil {
+					return err
+				}
 			}
-			nn += n
-		}
-
-		if o.optType != NoArgument {
-			if n, err = fmt.Fprintf(w, " "); err != nil {
+			if _, err := fmt.Fprintf(w, "%s%s:\n", indentStr, g.name); err != nil {
 				return err
 			}
-			nn += n
-			if n, err = fmt.Fprintf(w, o.argText); err != nil {
+		}
+		for _, o := range g.opts {
+			err := writeEntry(o.usageTerm(hasOpt), o.usage(p.envVar(o)))
+			if err != nil {
 				return err
 			}
-			nn += n
 		}
+	}
 
-		ws := strings.Repeat(" ", maxUsage-nn)
-		if _, err = fmt.Fprintf(w, ws); err != nil {
-			return err
-		}
+	if len(p.commands) == 0 {
+		return nil
+	}
 
-		if _, err = fmt.Fprintf(w, o.desc); err != nil {
+	// "INDENT  [COMMAND][VARSPACE][DESCRIP]"
+	//     -v, --verbose    The verbose description.
+	//
+	//     Commands:
+	//       remote         The remote description.
+	if len(opts) > 0 {
+		if _, err := fmt.Fprintln(w); err != nil {
 			return err
 		}
-
-		if _, err = fmt.Fprintln(w); err != nil {
+	}
+	if _, err := fmt.Fprintf(w, "%sCommands:\n", indentStr); err != nil {
+		return err
+	}
+	for x, c := range p.commands {
+		if err := writeEntry(cmdTerms[x], c.desc); err != nil {
 			return err
 		}
 	}
@@ -2,9 +2,12 @@ package gotopt
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -201,6 +204,17 @@ func TestParserNoTime(t *testing.T) {
 	a2(t, testParse(t, "tipnt08", "-n", "--time"))
 }
 
+func TestParserNoTimeLongName(t *testing.T) {
+	err := testParse(t, "tipntl01", "effie", "--ti").err.(*ErrRequiredArg)
+	assert.EqualValues(t, 't', err.Opt)
+	assert.Equal(t, "time", err.LongName)
+	assert.EqualError(t, err, "arg required for opt '--time'")
+
+	err = testParse(t, "tipntl02", "-t").err.(*ErrRequiredArg)
+	assert.Equal(t, "", err.LongName)
+	assert.EqualError(t, err, "arg required for opt 't'")
+}
+
 func TestParserUnknownOpt(t *testing.T) {
 	a1 := func(t *testing.T, r *parseTestResult, u string) {
 		assert.True(t, r.tfnd)
@@ -416,6 +430,244 @@ func TestParserDiffStates(t *testing.T) {
 	assert.Equal(t, "47", o.Value())
 }
 
+func TestParserAlias(t *testing.T) {
+	p := NewParser()
+	p.Opt('c', "color", RequiredArgument, "when", "When to use color",
+		Alias("colour"))
+	p.Opt(0, "dry-run", NoArgument, "", "Do nothing", Alias("dryrun"))
+	p.Opt(0, "drop", NoArgument, "", "Drop it")
+
+	ps, err := p.ParseAll([]string{
+		"tpalias01", "--colour=always", "--dryrun", "--color", "auto",
+		"-cnever", "--dry", "--colo=x"})
+	assert.NoError(t, err)
+	ps = ps.First()
+
+	exp := []struct {
+		opt      int
+		longName string
+		alias    string
+		value    string
+	}{
+		{'c', "color", "colour", "always"},
+		{0, "dry-run", "dryrun", ""},
+		{'c', "color", "", "auto"},
+		{'c', "color", "", "never"},
+		{0, "dry-run", "", ""},
+		{'c', "color", "", "x"},
+	}
+	for x, e := range exp {
+		o := ps.Value().(Option)
+		assert.EqualValues(t, e.opt, o.Opt(), x)
+		assert.Equal(t, e.longName, o.LongName(), x)
+		assert.Equal(t, e.alias, o.Alias(), x)
+		assert.Equal(t, e.value, o.Value(), x)
+		ps, _ = ps.Next()
+	}
+	assert.Nil(t, ps)
+
+	// "--dr" abbreviates two different options
+	ps, err = p.ParseAll([]string{"tpalias02", "--dr"})
+	assert.NoError(t, err)
+	assert.IsType(t, &ErrUnknownOpt{}, ps.First().Value())
+
+	usage := `    -c, --color, --colour when When to use color
+        --dry-run, --dryrun    Do nothing
+        --drop                 Drop it
+`
+	assert.Equal(t, usage, p.Usage())
+	assert.Equal(t, []string{"--dry-run", "--dryrun", "--drop"},
+		p.Completions([]string{"--dr"}))
+	assert.Equal(t, []string{"--colour="}, p.Completions([]string{"--colou"}))
+}
+
+func TestParserNegatable(t *testing.T) {
+	var color bool
+	p := NewParser()
+	p.BoolVar(&color, 'c', "color", "Use color", Negatable(), Alias("colour"))
+	p.Opt(0, "name", NoArgument, "", "The name")
+
+	ps, err := p.ParseAll([]string{
+		"tpneg01", "--color", "--no-colour", "-c", "--no-col", "--na"})
+	assert.NoError(t, err)
+	ps = ps.First()
+
+	exp := []struct {
+		alias string
+		value bool
+	}{
+		{"", true},
+		{"colour", false},
+		{"", true},
+		{"", false},
+	}
+	for x, e := range exp {
+		o := ps.Value().(Option)
+		assert.EqualValues(t, 'c', o.Opt(), x)
+		assert.Equal(t, "color", o.LongName(), x)
+		assert.Equal(t, e.alias, o.Alias(), x)
+		assert.Equal(t, e.value, o.Bool(), x)
+		ps, _ = ps.Next()
+	}
+	assert.Equal(t, "name", ps.Value().(Option).LongName())
+	assert.True(t, ps.Value().(Option).Bool())
+	assert.False(t, color)
+
+	// "--n" abbreviates both --name and --no-color
+	ps, err = p.ParseAll([]string{"tpneg02", "--n"})
+	assert.NoError(t, err)
+	assert.IsType(t, &ErrUnknownOpt{}, ps.First().Value())
+
+	usage := `    -c, --[no-]color, --[no-]colour Use color
+        --name                      The name
+`
+	assert.Equal(t, usage, p.Usage())
+	assert.Equal(t, []string{"--no-color", "--no-colour"},
+		p.Completions([]string{"--no"}))
+}
+
+func TestParserNegatableSources(t *testing.T) {
+	defer setTestEnv("GOTOPT_TEST_PAGER", "false")()
+
+	p := NewParser()
+	p.Opt('p', "pager", NoArgument, "", "",
+		Negatable(), Env("GOTOPT_TEST_PAGER"))
+	p.Opt(0, "color", NoArgument, "", "", Negatable())
+	p.Opt(0, "verbose", NoArgument, "", "", Negatable())
+	assert.NoError(t, p.LoadConfigFrom(
+		strings.NewReader("no-color\n"), "rc", IniConfig))
+	assert.NoError(t, p.LoadConfigFrom(
+		strings.NewReader(`{"verbose": false}`), "rc.json", JSONConfig))
+
+	ps, err := p.ParseAll([]string{"tpneg03"})
+	assert.NoError(t, err)
+	ps = ps.First()
+
+	for _, name := range []string{"pager", "color", "verbose"} {
+		o := ps.Value().(Option)
+		assert.Equal(t, name, o.LongName())
+		assert.False(t, o.Bool(), name)
+		ps, _ = ps.Next()
+	}
+	assert.Nil(t, ps)
+}
+
+func TestParserRunes(t *testing.T) {
+	var lambda string
+	p := NewParser()
+	p.StringVar(&lambda, 'λ', "lambda", "fn", "The λ function")
+	p.Opt('é', "", NoArgument, "", "Étendre")
+	p.Opt('n', "naïve", NoArgument, "", "Be naïve")
+	p.MutuallyExclusive("-é", "n")
+
+	ps, err := p.ParseAll([]string{
+		"tprune01", "-éλid", "--naï", "-ü", "--λ"})
+	assert.NoError(t, err)
+	ps = ps.First()
+
+	o := ps.Value().(Option)
+	assert.EqualValues(t, 'é', o.Opt())
+	ps, _ = ps.Next()
+	o = ps.Value().(Option)
+	assert.EqualValues(t, 'λ', o.Opt())
+	assert.Equal(t, "id", lambda)
+	ps, _ = ps.Next()
+	o = ps.Value().(Option)
+	assert.Equal(t, "naïve", o.LongName())
+
+	ps, _ = ps.Next()
+	assert.Equal(t, &ErrUnknownOpt{'ü', ""}, ps.Value())
+	assert.EqualError(t, ps.Value().(error), "unknown option '-ü'")
+	ps, _ = ps.Next()
+	assert.Equal(t, &ErrUnknownOpt{0, "λ"}, ps.Value())
+	ps, _ = ps.Next()
+	assert.IsType(t, &ErrConstraint{}, ps.Value())
+
+	usage := `    -λ, --lambda fn The λ function
+    -é              Étendre
+    -n, --naïve     Be naïve
+`
+	assert.Equal(t, usage, p.Usage())
+}
+
+func TestParserParseContext(t *testing.T) {
+	p := NewParser()
+	p.Opt('n', "name", NoArgument, "", "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c, err := p.ParseContext(ctx, []string{
+		"tpctx01", "-n", "-f", "-n", "-n", "-n", "-n", "effie"})
+	assert.NoError(t, err)
+
+	ps := <-c
+	assert.IsType(t, &parsedOpt{}, ps.Value())
+	ps = <-c
+	assert.IsType(t, &ErrUnknownOpt{}, ps.Value())
+	cancel()
+
+	// the parse operation stops and closes the channel without the
+	// remaining states being received
+	n := 0
+	timeout := time.After(5 * time.Second)
+	for closed := false; !closed; {
+		select {
+		case _, ok := <-c:
+			closed = !ok
+			if ok {
+				n++
+			}
+		case <-timeout:
+			t.Fatal("channel not closed")
+		}
+	}
+	assert.True(t, n <= 1)
+}
+
+func TestParserParseBuffer(t *testing.T) {
+	p := NewParser()
+	p.Opt('n', "name", NoArgument, "", "")
+	p.SetParseBuffer(8)
+
+	c, err := p.Parse([]string{"tpbuf01", "-nn", "effie"})
+	assert.NoError(t, err)
+	assert.Equal(t, 8, cap(c))
+
+	// the buffer holds all of the states, so the parse operation completes
+	// and closes the channel even though nothing has been received
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for len(c) < 3 {
+		select {
+		case <-ctx.Done():
+			t.Fatal("states not buffered")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	states := []ParserState{}
+	for ps := range c {
+		states = append(states, ps)
+	}
+	assert.Len(t, states, 3)
+	assert.Equal(t, []string{"effie"}, states[2].Value())
+}
+
+func TestParserParseBufferNegative(t *testing.T) {
+	p := NewParser()
+	p.Opt('n', "name", NoArgument, "", "")
+	p.SetParseBuffer(-1)
+
+	c, err := p.Parse([]string{"tpbuf02", "-n"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cap(c))
+	for range c {
+		// do nothing
+	}
+
+	ps, err := p.ParseAll([]string{"tpbuf02", "-n", "effie"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"effie"}, ps.Value())
+}
+
 func newTestParser() Parser {
 	p := NewParser()
 	p.Opt('n', "name", NoArgument, "", "")
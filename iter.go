@@ -0,0 +1,167 @@
+package gotopt
+
+// OptIterator iterates over the options parsed from an argument list. Unlike
+// Parse, an OptIterator parses the arguments on the caller's goroutine, one
+// option at a time as Next is called:
+//
+//     it := p.Iter(os.Args)
+//     for it.Next() {
+//         if err := it.Err(); err != nil {
+//             return err
+//         }
+//         switch it.Option().Opt() {
+//         case 'n':
+//             ...
+//         }
+//     }
+//     args := it.Args()
+//
+// When the iterator reaches the name of one of the parser's commands, it
+// continues with the options of that command, and Command returns the
+// command.
+//
+// An OptIterator only parses the argument list. Options are not read from
+// the environment or configuration files, options are not sent with their
+// default values, options are not validated, and DeprecationWarnings are not
+// produced.
+type OptIterator struct {
+	p          *parser
+	sc         *optScanner
+	argvOffset int
+	optIndices map[*optDef]int
+	counts     map[*optDef]int
+	opt        Option
+	err        error
+	cmd        Command
+	args       []string
+	done       bool
+}
+
+// Iter returns an OptIterator for the options parsed from the supplied
+// arguments.
+func (p *parser) Iter(argv []string) *OptIterator {
+	it := &OptIterator{
+		p:          p,
+		optIndices: map[*optDef]int{},
+		counts:     map[*optDef]int{},
+	}
+	if len(argv) == 0 {
+		it.err = ErrEmptyArgList
+		return it
+	}
+	it.sc = p.newOptScanner(argv)
+	return it
+}
+
+// Next advances the iterator to the next option or error and returns a flag
+// indicating whether or not there is one. Once Next returns false, Args
+// returns the remaining non-option arguments.
+func (it *OptIterator) Next() bool {
+	it.opt = nil
+	if it.sc == nil {
+		// the iterator was created with an empty argument list, and the
+		// error is returned once
+		if it.err == nil || it.done {
+			return false
+		}
+		it.done = true
+		return true
+	}
+	it.err = nil
+
+	for !it.done {
+		sc, gop := it.sc, it.sc.gop
+		opt, o := sc.next()
+
+		switch {
+		case o != nil:
+			ps := newParsedOptState(
+				o, gop.OptArg, sc.negated, FromArgv, it.optIndices)
+			po, ok := ps.value.(*parsedOpt)
+			if !ok {
+				it.err = ps.value.(error)
+				return true
+			}
+			po.argvIndex = it.argvOffset + optArgvIndex(sc.argv, gop, o)
+			po.alias = sc.alias
+			po.setCount(it.counts)
+			it.opt = po
+			return true
+		case opt == -1:
+			if it.nextCommand() {
+				return true
+			}
+		case opt == 0:
+			// a long option that sets a flag
+		case opt == ':':
+			it.err = sc.missingArg()
+			return true
+		default:
+			it.err = &ErrUnknownOpt{gop.OptOpt, gop.OptArg}
+			return true
+		}
+	}
+
+	return false
+}
+
+// nextCommand handles the end of the current parser's options. If the next
+// argument is the name of one of the parser's commands, the iterator
+// continues with the command's options; otherwise the iterator is done. A
+// flag is returned indicating whether or not the iterator has an error.
+func (it *OptIterator) nextCommand() bool {
+	argv, optInd := it.sc.argv, it.sc.gop.OptInd
+	if optInd >= len(argv) {
+		it.args = []string{}
+		it.done = true
+		return false
+	}
+
+	if len(it.p.commands) == 0 {
+		it.args = argv[optInd:]
+		it.done = true
+		return false
+	}
+
+	name := argv[optInd]
+	cmd, ok := it.p.commandsByName[name]
+	if !ok {
+		it.args = argv[optInd:]
+		it.err = &ErrUnknownCommand{name}
+		it.done = true
+		return true
+	}
+
+	it.p = cmd
+	it.cmd = &parsedCmd{cmd}
+	it.argvOffset += optInd
+	it.sc = cmd.newOptScanner(argv[optInd:])
+	return false
+}
+
+// Option returns the option to which the iterator has advanced, or nil if
+// the iterator has advanced to an error.
+func (it *OptIterator) Option() Option {
+	return it.opt
+}
+
+// Err returns the error to which the iterator has advanced, or nil if the
+// iterator has advanced to an option. An option whose argument is rejected
+// is an error, ex. an ErrInvalidArg.
+func (it *OptIterator) Err() error {
+	return it.err
+}
+
+// Command returns the command whose options the iterator is parsing, or nil
+// if the iterator is parsing the options of the parser with which it was
+// created.
+func (it *OptIterator) Command() Command {
+	return it.cmd
+}
+
+// Args returns the non-option arguments that follow the options once Next
+// returns false. If the iterator stopped at an unknown command, the first
+// argument is the command's name.
+func (it *OptIterator) Args() []string {
+	return it.args
+}
@@ -0,0 +1,27 @@
+//go:build go1.23
+// +build go1.23
+
+package gotopt
+
+import "iter"
+
+// All returns the remaining options and errors of the iterator as a
+// sequence:
+//
+//     for o, err := range p.Iter(os.Args).All() {
+//         if err != nil {
+//             return err
+//         }
+//         ...
+//     }
+//
+// Either the option or the error of each pair is nil.
+func (it *OptIterator) All() iter.Seq2[Option, error] {
+	return func(yield func(Option, error) bool) {
+		for it.Next() {
+			if !yield(it.Option(), it.Err()) {
+				return
+			}
+		}
+	}
+}
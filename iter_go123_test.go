@@ -0,0 +1,40 @@
+//go:build go1.23
+// +build go1.23
+
+package gotopt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptIteratorAll(t *testing.T) {
+	p := NewParser()
+	p.Opt('n', "name", NoArgument, "", "")
+	p.Opt('t', "time", RequiredArgument, "", "")
+
+	it := p.Iter([]string{"tpiterall01", "-n", "-x", "-t37", "-n", "effie"})
+
+	opts := []int{}
+	errs := 0
+	for o, err := range it.All() {
+		if err != nil {
+			errs++
+			continue
+		}
+		opts = append(opts, o.Opt())
+		if o.Opt() == 't' {
+			break
+		}
+	}
+	assert.Equal(t, []int{'n', 't'}, opts)
+	assert.Equal(t, 1, errs)
+
+	// the iterator resumes after the option at which the loop stopped
+	for o, err := range it.All() {
+		assert.NoError(t, err)
+		assert.EqualValues(t, 'n', o.Opt())
+	}
+	assert.Equal(t, []string{"effie"}, it.Args())
+}
@@ -0,0 +1,87 @@
+package gotopt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptIterator(t *testing.T) {
+	var tm int
+	p := NewParser()
+	p.Opt('n', "name", NoArgument, "", "")
+	p.IntVar(&tm, 't', "time", "", "")
+	p.Opt('v', "verbose", NoArgument, "", "", Counter())
+
+	it := p.Iter([]string{
+		"tpiter01", "-nvt37", "effie", "-f", "--time=x", "-vv", "--", "-n"})
+
+	assert.True(t, it.Next())
+	assert.NoError(t, it.Err())
+	assert.EqualValues(t, 'n', it.Option().Opt())
+	assert.Equal(t, 1, it.Option().(*parsedOpt).argvIndex)
+
+	assert.True(t, it.Next())
+	assert.EqualValues(t, 'v', it.Option().Opt())
+
+	assert.True(t, it.Next())
+	assert.EqualValues(t, 't', it.Option().Opt())
+	assert.Equal(t, "37", it.Option().Value())
+	assert.Equal(t, 37, tm)
+
+	assert.True(t, it.Next())
+	assert.Nil(t, it.Option())
+	assert.Equal(t, &ErrUnknownOpt{'f', ""}, it.Err())
+
+	assert.True(t, it.Next())
+	assert.IsType(t, &ErrInvalidArg{}, it.Err())
+
+	assert.True(t, it.Next())
+	assert.EqualValues(t, 'v', it.Option().Opt())
+	assert.Equal(t, 2, it.Option().Count())
+	assert.True(t, it.Next())
+	assert.Equal(t, 3, it.Option().Count())
+
+	assert.False(t, it.Next())
+	assert.False(t, it.Next())
+	assert.Nil(t, it.Option())
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"effie", "-n"}, it.Args())
+	assert.Nil(t, it.Command())
+}
+
+func TestOptIteratorCommands(t *testing.T) {
+	p := NewParser()
+	p.Opt('v', "verbose", NoArgument, "", "", Persistent())
+	remote := p.Command("remote", "")
+	remote.Opt('f', "force", NoArgument, "", "")
+
+	it := p.Iter([]string{"tpiter02", "-v", "remote", "-fv", "origin"})
+
+	assert.True(t, it.Next())
+	assert.EqualValues(t, 'v', it.Option().Opt())
+	assert.Nil(t, it.Command())
+
+	assert.True(t, it.Next())
+	assert.EqualValues(t, 'f', it.Option().Opt())
+	assert.Equal(t, 3, it.Option().(*parsedOpt).argvIndex)
+	assert.Equal(t, "remote", it.Command().Name())
+
+	assert.True(t, it.Next())
+	assert.EqualValues(t, 'v', it.Option().Opt())
+	assert.Equal(t, 1, it.Option().Index())
+
+	assert.False(t, it.Next())
+	assert.Equal(t, []string{"origin"}, it.Args())
+
+	it = p.Iter([]string{"tpiter03", "fetch", "-v"})
+	assert.True(t, it.Next())
+	assert.Equal(t, &ErrUnknownCommand{"fetch"}, it.Err())
+	assert.False(t, it.Next())
+	assert.Equal(t, []string{"fetch", "-v"}, it.Args())
+
+	it = p.Iter(nil)
+	assert.True(t, it.Next())
+	assert.Equal(t, ErrEmptyArgList, it.Err())
+	assert.False(t, it.Next())
+}
@@ -0,0 +1,13 @@
+package gotopt
+
+import (
+	"os"
+	"testing"
+)
+
+// TestMain clears COLUMNS so that the usage text is wrapped to the default
+// width no matter the terminal in which the tests are run.
+func TestMain(m *testing.M) {
+	os.Unsetenv("COLUMNS")
+	os.Exit(m.Run())
+}
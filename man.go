@@ -0,0 +1,234 @@
+package gotopt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ManPageMeta is the information about a program that is written to its man
+// page along with the definitions of the program's options and commands.
+type ManPageMeta struct {
+	// Section is the manual section. The default section is "1".
+	Section string
+
+	// Date is the date of the program's last revision, ex. "March 2019".
+	Date string
+
+	// Source is the source of the program, ex. "gotopt 1.0".
+	Source string
+
+	// Manual is the title of the manual, ex. "User Commands".
+	Manual string
+
+	// Summary is the one-line description in the NAME section.
+	Summary string
+
+	// Description is the text of the DESCRIPTION section. Paragraphs are
+	// separated by blank lines.
+	Description string
+
+	// Environment describes the environment variables that are not read
+	// into options. The ENVIRONMENT section lists these after the
+	// environment variables of the options.
+	Environment []ManPageItem
+
+	// ExitStatus describes the program's exit status values. The default
+	// values are 0 for success and 1 for failure.
+	ExitStatus []ManPageItem
+}
+
+// ManPageItem is a term and its description in a man page section.
+type ManPageItem struct {
+	Term string
+	Desc string
+}
+
+// WriteManPage writes a man(7) page to the provided stream.
+func (p *parser) WriteManPage(w io.Writer, meta ManPageMeta) error {
+	b := &bytes.Buffer{}
+
+	name := strings.Join(append([]string{p.progName()}, p.cmdPath()...), "-")
+	section := meta.Section
+	if section == "" {
+		section = "1"
+	}
+
+	fmt.Fprintf(b, ".TH %s %s %s %s %s\n",
+		roffQuote(strings.ToUpper(name)), roffQuote(section),
+		roffQuote(meta.Date), roffQuote(meta.Source), roffQuote(meta.Manual))
+
+	b.WriteString(".SH NAME\n")
+	if meta.Summary != "" {
+		fmt.Fprintf(b, "%s \\- %s\n", roffEscape(name), roffEscape(meta.Summary))
+	} else {
+		fmt.Fprintf(b, "%s\n", roffEscape(name))
+	}
+
+	opts := p.visibleOpts()
+
+	b.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(b, ".B %s\n", roffEscape(strings.Join(
+		append([]string{p.progName()}, p.cmdPath()...), " ")))
+	for _, o := range opts {
+		fmt.Fprintf(b, "%s\n", o.manSynopsis())
+	}
+	if len(p.commands) > 0 {
+		b.WriteString("\\fIcommand\\fR [\\fIargs\\fR]\n")
+	}
+
+	if meta.Description != "" {
+		b.WriteString(".SH DESCRIPTION\n")
+		writeRoffParagraphs(b, meta.Description)
+	}
+
+	if len(opts) > 0 {
+		b.WriteString(".SH OPTIONS\n")
+		for _, o := range opts {
+			b.WriteString(".TP\n")
+			fmt.Fprintf(b, "%s\n", o.manTerm())
+			if desc := o.usage(""); desc != "" {
+				fmt.Fprintf(b, "%s\n", roffEscape(desc))
+			}
+		}
+	}
+
+	if len(p.commands) > 0 {
+		b.WriteString(".SH COMMANDS\n")
+		for _, c := range p.commands {
+			b.WriteString(".TP\n")
+			fmt.Fprintf(b, ".B %s\n", roffEscape(c.name))
+			if c.desc != "" {
+				fmt.Fprintf(b, "%s\n", roffEscape(c.desc))
+			}
+		}
+	}
+
+	env := []ManPageItem{}
+	for _, o := range opts {
+		if name := p.envVar(o); name != "" {
+			env = append(env, ManPageItem{
+				name,
+				fmt.Sprintf("Sets the option %s.", optName(o)),
+			})
+		}
+	}
+	env = append(env, meta.Environment...)
+	if len(env) > 0 {
+		b.WriteString(".SH ENVIRONMENT\n")
+		writeRoffItems(b, env)
+	}
+
+	exitStatus := meta.ExitStatus
+	if len(exitStatus) == 0 {
+		exitStatus = []ManPageItem{
+			{"0", "Success."},
+			{"1", "An error occurred."},
+		}
+	}
+	b.WriteString(".SH EXIT STATUS\n")
+	writeRoffItems(b, exitStatus)
+
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// manSynopsis returns the option as shown in the SYNOPSIS section of a man
+// page, ex. "[\fB\-t\fR \fIepoch\fR]". Required options are not enclosed in
+// brackets.
+func (o *optDef) manSynopsis() string {
+	var s string
+	arg := roffEscape(o.argName())
+	if o.opt > 0 {
+		s = fmt.Sprintf("\\fB\\-%s\\fR", roffEscape(string(rune(o.opt))))
+		switch {
+		case o.counter:
+			// the option character of a counter never takes an argument
+		case o.optType == RequiredArgument:
+			s += fmt.Sprintf(" \\fI%s\\fR", arg)
+		case o.optType == OptionalArgument:
+			s += fmt.Sprintf("[\\fI%s\\fR]", arg)
+		}
+	} else {
+		s = fmt.Sprintf("\\fB\\-\\-%s\\fR", roffEscape(o.longName))
+		switch o.optType {
+		case RequiredArgument:
+			s += fmt.Sprintf("=\\fI%s\\fR", arg)
+		case OptionalArgument:
+			s += fmt.Sprintf("[=\\fI%s\\fR]", arg)
+		}
+	}
+	if o.required {
+		return s
+	}
+	return "[" + s + "]"
+}
+
+// manTerm returns the option as shown in the OPTIONS section of a man page,
+// ex. "\fB\-t\fR, \fB\-\-time\fR=\fIepoch\fR".
+func (o *optDef) manTerm() string {
+	arg := roffEscape(o.argName())
+	forms := []string{}
+	if o.opt > 0 {
+		s := fmt.Sprintf("\\fB\\-%s\\fR", roffEscape(string(rune(o.opt))))
+		if o.longName == "" && !o.counter {
+			switch o.optType {
+			case RequiredArgument:
+				s += fmt.Sprintf(" \\fI%s\\fR", arg)
+			case OptionalArgument:
+				s += fmt.Sprintf("[\\fI%s\\fR]", arg)
+			}
+		}
+		forms = append(forms, s)
+	}
+	for _, name := range o.longNames() {
+		s := fmt.Sprintf("\\fB%s\\fR", roffEscape(o.longForm(name)))
+		switch o.optType {
+		case RequiredArgument:
+			s += fmt.Sprintf("=\\fI%s\\fR", arg)
+		case OptionalArgument:
+			s += fmt.Sprintf("[=\\fI%s\\fR]", arg)
+		}
+		forms = append(forms, s)
+	}
+	return strings.Join(forms, ", ")
+}
+
+// roffEscape escapes the text for use in a roff document.
+func roffEscape(s string) string {
+	s = strings.NewReplacer(`\`, `\e`, `-`, `\-`).Replace(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// roffQuote escapes and quotes the text for use as a macro argument.
+func roffQuote(s string) string {
+	return `"` + strings.Replace(roffEscape(s), `"`, `""`, -1) + `"`
+}
+
+// writeRoffParagraphs writes the text's paragraphs, which are separated by
+// blank lines.
+func writeRoffParagraphs(b *bytes.Buffer, text string) {
+	for x, para := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		if x > 0 {
+			b.WriteString(".PP\n")
+		}
+		for _, line := range strings.Split(strings.TrimSpace(para), "\n") {
+			fmt.Fprintf(b, "%s\n", roffEscape(strings.TrimSpace(line)))
+		}
+	}
+}
+
+// writeRoffItems writes the items as a list of tagged paragraphs.
+func writeRoffItems(b *bytes.Buffer, items []ManPageItem) {
+	for _, i := range items {
+		b.WriteString(".TP\n")
+		fmt.Fprintf(b, ".B %s\n", roffEscape(i.Term))
+		if i.Desc != "" {
+			fmt.Fprintf(b, "%s\n", roffEscape(i.Desc))
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package gotopt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteManPage(t *testing.T) {
+	defer setTestEnv("GOTOPT_TEST_TIME", "")()
+
+	p := newParser()
+	p.name = "tool"
+	p.Opt('n', "name", NoArgument, "", "A flag indicating the name is a trailing arg")
+	p.Opt('t', "time", RequiredArgument, "epoch", "The epoch",
+		Required(), Env("GOTOPT_TEST_TIME"))
+	p.Opt('x', "xist", OptionalArgument, "val", "A value", Default("42"))
+	p.Opt(0, "fast", OptionalArgument, "mph", "How fast to go")
+	p.Opt('o', "", OptionalArgument, "asc|desc", ".The current order")
+	p.Command("remote", "Manage remotes")
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, p.WriteManPage(b, ManPageMeta{
+		Date:        "March 2019",
+		Source:      "tool 1.0",
+		Manual:      "User Commands",
+		Summary:     "a tool",
+		Description: "The tool does\nthings.\n\nIt's a \\ tool.",
+		Environment: []ManPageItem{{"HOME", "The home directory."}},
+	}))
+
+	exp := `.TH "TOOL" "1" "March 2019" "tool 1.0" "User Commands"
+.SH NAME
+tool \- a tool
+.SH SYNOPSIS
+.B tool
+[\fB\-n\fR]
+\fB\-t\fR \fIepoch\fR
+[\fB\-x\fR[\fIval\fR]]
+[\fB\-\-fast\fR[=\fImph\fR]]
+[\fB\-o\fR[\fIasc|desc\fR]]
+\fIcommand\fR [\fIargs\fR]
+.SH DESCRIPTION
+The tool does
+things.
+.PP
+It's a \e tool.
+.SH OPTIONS
+.TP
+\fB\-n\fR, \fB\-\-name\fR
+A flag indicating the name is a trailing arg
+.TP
+\fB\-t\fR, \fB\-\-time\fR=\fIepoch\fR
+The epoch
+.TP
+\fB\-x\fR, \fB\-\-xist\fR[=\fIval\fR]
+A value (default: 42)
+.TP
+\fB\-\-fast\fR[=\fImph\fR]
+How fast to go
+.TP
+\fB\-o\fR[\fIasc|desc\fR]
+\&.The current order
+.SH COMMANDS
+.TP
+.B remote
+Manage remotes
+.SH ENVIRONMENT
+.TP
+.B GOTOPT_TEST_TIME
+Sets the option \-\-time.
+.TP
+.B HOME
+The home directory.
+.SH EXIT STATUS
+.TP
+.B 0
+Success.
+.TP
+.B 1
+An error occurred.
+`
+	assert.Equal(t, exp, b.String())
+}
+
+func TestWriteManPageCommand(t *testing.T) {
+	p := newParser()
+	p.name = "tool"
+	c := p.Command("remote", "")
+	c.Opt('v', "", NoArgument, "", "")
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, c.WriteManPage(b, ManPageMeta{
+		Section:    "8",
+		ExitStatus: []ManPageItem{{"2", "Bad remote."}},
+	}))
+	assert.Equal(t, `.TH "TOOL\-REMOTE" "8" "" "" ""
+.SH NAME
+tool\-remote
+.SH SYNOPSIS
+.B tool remote
+[\fB\-v\fR]
+.SH OPTIONS
+.TP
+\fB\-v\fR
+.SH EXIT STATUS
+.TP
+.B 2
+Bad remote.
+`, b.String())
+}
@@ -0,0 +1,105 @@
+package gotopt
+
+import (
+	"os"
+	"strconv"
+)
+
+// OptModifier modifies the definition of an option when the option is
+// registered with a Parser.
+type OptModifier func(o *optDef)
+
+// Persistent marks an option as persistent. A persistent option is accepted
+// by the parser with which it's registered as well as by the parsers of all
+// of that parser's commands.
+func Persistent() OptModifier {
+	return func(o *optDef) {
+		o.persistent = true
+	}
+}
+
+// Group places an option in the named group. The usage text lists the
+// options in each group under a heading with the group's name, after the
+// options that are not in a group. The groups are listed in the order in
+// which their first options are registered.
+func Group(name string) OptModifier {
+	return func(o *optDef) {
+		o.group = name
+	}
+}
+
+// Alias registers aliases of an option's long name, ex. "colour" for the
+// option "color". An option given by one of its aliases is sent with the
+// option's long name, and its Alias function returns the alias. An
+// abbreviation that matches only the long name and aliases of one option is
+// not ambiguous.
+func Alias(names ...string) OptModifier {
+	return func(o *optDef) {
+		o.aliases = append(o.aliases, names...)
+	}
+}
+
+// Negatable makes an option that does not take an argument negatable. The
+// parser accepts the option's long name and each of its aliases preceded by
+// "no-", ex. --no-color, which turns the option off. A negated option is sent
+// like the option itself, but its Bool function returns false and the Value to
+// which it's bound is set with the string "false". When the option is given
+// more than once, the last one given wins.
+func Negatable() OptModifier {
+	return func(o *optDef) {
+		o.negatable = true
+	}
+}
+
+// Counter makes an option a counting option. A counting option takes an
+// optional argument that must be a non-negative integer, regardless of the
+// OptionTypes with which it's registered. The option character of a counting
+// option never takes an argument, so the option may be repeated in a
+// cluster, ex. -vvv, and each occurrence increments the option's count. The
+// long name given an argument, ex. --verbose=3, sets the count to the
+// argument. The count is returned by each Option's Count function.
+func Counter() OptModifier {
+	return func(o *optDef) {
+		o.counter = true
+	}
+}
+
+// Hidden hides an option. A hidden option is accepted by the parser but is
+// omitted from the usage text, the generated documentation, and the
+// completion candidates.
+func Hidden() OptModifier {
+	return func(o *optDef) {
+		o.hidden = true
+	}
+}
+
+// Deprecated marks an option as deprecated in favor of the replacement,
+// ex. "--output". A DeprecationWarning is sent immediately before each
+// ParserState for the option when the option is given. The replacement may be
+// empty if the option has no replacement.
+func Deprecated(replacement string) OptModifier {
+	return func(o *optDef) {
+		o.deprecated = true
+		o.replacement = replacement
+	}
+}
+
+// Experimental marks an option as experimental. An experimental option is
+// accepted by the parser only if the named environment variable is set to a
+// true value as parsed by strconv.ParseBool; otherwise the option is unknown,
+// is ignored in the environment and configuration files, and is hidden.
+func Experimental(envVar string) OptModifier {
+	return func(o *optDef) {
+		o.experimental = envVar
+	}
+}
+
+// enabled returns a flag indicating whether or not the option is accepted by
+// the parser. Only experimental options may be disabled.
+func (o *optDef) enabled() bool {
+	if o.experimental == "" {
+		return true
+	}
+	b, _ := strconv.ParseBool(os.Getenv(o.experimental))
+	return b
+}
@@ -0,0 +1,200 @@
+package gotopt
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// refDocOpt is an option as listed in the reference documentation.
+type refDocOpt struct {
+	short    string
+	long     string
+	arg      string
+	argType  string
+	defValue string
+	desc     string
+}
+
+// refDocOpts returns the parser's options as listed in the reference
+// documentation. The options' argument placeholders and descriptions are
+// those shown by PrintAndIndentUsage, ex. an optional argument's placeholder
+// is enclosed in brackets.
+func (p *parser) refDocOpts() []refDocOpt {
+	docs := []refDocOpt{}
+	for _, o := range p.visibleOpts() {
+		d := refDocOpt{
+			defValue: o.defValue,
+			desc:     o.description(),
+		}
+		if o.opt > 0 {
+			d.short = fmt.Sprintf("-%c", o.opt)
+		}
+		forms := []string{}
+		for _, name := range o.longNames() {
+			forms = append(forms, o.longForm(name))
+		}
+		d.long = strings.Join(forms, ", ")
+		switch {
+		case o.counter && o.longName == "":
+			// the option character of a counter never takes an argument
+		case o.optType == RequiredArgument:
+			d.arg, d.argType = o.argText, "required"
+		case o.optType == OptionalArgument:
+			d.arg, d.argType = o.argText, "optional"
+		}
+		if env := p.envVar(o); env != "" {
+			d.desc = strings.TrimSpace(fmt.Sprintf("%s [$%s]", d.desc, env))
+		}
+		docs = append(docs, d)
+	}
+	return docs
+}
+
+// refDocTitle returns the title of the parser's reference documentation,
+// ex. "prog remote add".
+func (p *parser) refDocTitle() string {
+	return strings.Join(append([]string{p.progName()}, p.cmdPath()...), " ")
+}
+
+// WriteMarkdown writes Markdown reference documentation for the parser and
+// its commands to the provided stream.
+func (p *parser) WriteMarkdown(w io.Writer) error {
+	b := &bytes.Buffer{}
+	depth := len(p.cmdPath())
+
+	p.walk(func(c *parser) {
+		level := len(c.cmdPath()) - depth + 1
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(b, "%s %s\n\n", strings.Repeat("#", level), c.refDocTitle())
+		if c.desc != "" {
+			fmt.Fprintf(b, "%s\n\n", c.desc)
+		}
+		fmt.Fprintf(b, "```\n%s %s\n```\n", c.refDocTitle(), c.synopsisArgs())
+
+		sub := strings.Repeat("#", level+1)
+
+		if opts := c.refDocOpts(); len(opts) > 0 {
+			fmt.Fprintf(b, "\n%s Options\n\n", sub)
+			b.WriteString("| Short | Long | Argument | Default | Description |\n")
+			b.WriteString("| --- | --- | --- | --- | --- |\n")
+			for _, o := range opts {
+				arg := ""
+				if o.arg != "" {
+					arg = fmt.Sprintf("%s (%s)", mdCode(o.arg), o.argType)
+				}
+				fmt.Fprintf(b, "| %s | %s | %s | %s | %s |\n",
+					mdCode(o.short), mdCode(o.long), arg,
+					mdCode(o.defValue), mdEscape(o.desc))
+			}
+		}
+
+		if len(c.commands) > 0 {
+			fmt.Fprintf(b, "\n%s Commands\n\n", sub)
+			b.WriteString("| Command | Description |\n")
+			b.WriteString("| --- | --- |\n")
+			for _, cmd := range c.commands {
+				fmt.Fprintf(b, "| %s | %s |\n",
+					mdCode(cmd.name), mdEscape(cmd.desc))
+			}
+		}
+	})
+
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// mdCode returns the text as inline code in a Markdown table cell.
+func mdCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + strings.Replace(s, "|", `\|`, -1) + "`"
+}
+
+// mdEscape escapes the text for use in a Markdown table cell.
+func mdEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`",
+		"<", "&lt;", ">", "&gt;", "\n", " ").Replace(s)
+}
+
+// WriteHTML writes HTML reference documentation for the parser and its
+// commands to the provided stream. The documentation is an HTML fragment
+// suitable for embedding in a page.
+func (p *parser) WriteHTML(w io.Writer) error {
+	b := &bytes.Buffer{}
+	depth := len(p.cmdPath())
+	esc := html.EscapeString
+
+	p.walk(func(c *parser) {
+		level := len(c.cmdPath()) - depth + 1
+		if level > 5 {
+			level = 5
+		}
+		id := strings.Replace(c.refDocTitle(), " ", "-", -1)
+
+		fmt.Fprintf(b, "<section id=\"%s\">\n", esc(id))
+		fmt.Fprintf(b, "<h%[1]d>%[2]s</h%[1]d>\n", level, esc(c.refDocTitle()))
+		if c.desc != "" {
+			fmt.Fprintf(b, "<p>%s</p>\n", esc(c.desc))
+		}
+		fmt.Fprintf(b, "<pre><code>%s %s</code></pre>\n",
+			esc(c.refDocTitle()), esc(c.synopsisArgs()))
+
+		if opts := c.refDocOpts(); len(opts) > 0 {
+			fmt.Fprintf(b, "<h%[1]d>Options</h%[1]d>\n", level+1)
+			b.WriteString("<table>\n")
+			b.WriteString("<thead><tr><th>Short</th><th>Long</th>" +
+				"<th>Argument</th><th>Default</th><th>Description</th>" +
+				"</tr></thead>\n")
+			b.WriteString("<tbody>\n")
+			for _, o := range opts {
+				arg := ""
+				if o.arg != "" {
+					arg = fmt.Sprintf("<code>%s</code> (%s)",
+						esc(o.arg), o.argType)
+				}
+				fmt.Fprintf(b, "<tr><td>%s</td><td>%s</td>"+
+					"<td>%s</td><td>%s</td><td>%s</td></tr>\n",
+					htmlCode(o.short), htmlCode(o.long),
+					arg, htmlCode(o.defValue), esc(o.desc))
+			}
+			b.WriteString("</tbody>\n")
+			b.WriteString("</table>\n")
+		}
+
+		if len(c.commands) > 0 {
+			fmt.Fprintf(b, "<h%[1]d>Commands</h%[1]d>\n", level+1)
+			b.WriteString("<table>\n")
+			b.WriteString("<thead><tr><th>Command</th>" +
+				"<th>Description</th></tr></thead>\n")
+			b.WriteString("<tbody>\n")
+			for _, cmd := range c.commands {
+				id := strings.Replace(cmd.refDocTitle(), " ", "-", -1)
+				fmt.Fprintf(b, "<tr><td><a href=\"#%s\"><code>%s</code></a>"+
+					"</td><td>%s</td></tr>\n",
+					esc(id), esc(cmd.name), esc(cmd.desc))
+			}
+			b.WriteString("</tbody>\n")
+			b.WriteString("</table>\n")
+		}
+
+		b.WriteString("</section>\n")
+	})
+
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// htmlCode returns the text as inline code in an HTML table cell.
+func htmlCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "<code>" + html.EscapeString(s) + "</code>"
+}
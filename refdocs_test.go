@@ -0,0 +1,92 @@
+package gotopt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	p := newParser()
+	p.name = "prog"
+	p.Opt('n', "name", NoArgument, "", "A flag indicating the name is a trailing arg")
+	p.Opt('t', "time", RequiredArgument, "epoch", "The epoch", Default("37"))
+	p.Opt(0, "fast", OptionalArgument, "mph", "How *fast* to go")
+	p.Opt('o', "", OptionalArgument, "asc|desc", "The <order>")
+	remote := p.Command("remote", "Manage remotes")
+	remote.Opt('f', "fetch", NoArgument, "", "Fetch the remote")
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, p.WriteMarkdown(b))
+	exp := "# prog\n\n" +
+		"```\nprog [options] <command>\n```\n\n" +
+		"## Options\n\n" +
+		"| Short | Long | Argument | Default | Description |\n" +
+		"| --- | --- | --- | --- | --- |\n" +
+		"| `-n` | `--name` |  |  | A flag indicating the name is a trailing arg |\n" +
+		"| `-t` | `--time` | `epoch` (required) | `37` | The epoch |\n" +
+		"|  | `--fast` | `[mph]` (optional) |  | How \\*fast\\* to go |\n" +
+		"| `-o` |  | `[asc\\|desc]` (optional) |  | The &lt;order&gt; |\n\n" +
+		"## Commands\n\n" +
+		"| Command | Description |\n" +
+		"| --- | --- |\n" +
+		"| `remote` | Manage remotes |\n\n" +
+		"## prog remote\n\n" +
+		"Manage remotes\n\n" +
+		"```\nprog remote [options]\n```\n\n" +
+		"### Options\n\n" +
+		"| Short | Long | Argument | Default | Description |\n" +
+		"| --- | --- | --- | --- | --- |\n" +
+		"| `-f` | `--fetch` |  |  | Fetch the remote |\n"
+	assert.Equal(t, exp, b.String())
+}
+
+func TestWriteMarkdownCounter(t *testing.T) {
+	p := newParser()
+	p.name = "prog"
+	p.Opt('v', "verbose", NoArgument, "", "Be verbose", Counter())
+	p.Opt('q', "", NoArgument, "", "Be quiet", Counter())
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, p.WriteMarkdown(b))
+	assert.Contains(t, b.String(),
+		"| `-v` | `--verbose` | `[n]` (optional) |  | Be verbose |\n"+
+			"| `-q` |  |  |  | Be quiet |\n")
+}
+
+func TestWriteHTML(t *testing.T) {
+	p := newParser()
+	p.name = "prog"
+	p.Opt(0, "fast", OptionalArgument, "mph", "How *fast* to go")
+	p.Opt('o', "", OptionalArgument, "asc|desc", "The <order>")
+	remote := p.Command("remote", "Manage remotes")
+	remote.Opt('f', "fetch", NoArgument, "", "Fetch the remote")
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, remote.WriteHTML(b))
+	exp := `<section id="prog-remote">
+<h1>prog remote</h1>
+<p>Manage remotes</p>
+<pre><code>prog remote [options]</code></pre>
+<h2>Options</h2>
+<table>
+<thead><tr><th>Short</th><th>Long</th><th>Argument</th><th>Default</th><th>Description</th></tr></thead>
+<tbody>
+<tr><td><code>-f</code></td><td><code>--fetch</code></td><td></td><td></td><td>Fetch the remote</td></tr>
+</tbody>
+</table>
+</section>
+`
+	assert.Equal(t, exp, b.String())
+
+	b.Reset()
+	assert.NoError(t, p.WriteHTML(b))
+	assert.Contains(t, b.String(), "<tr><td></td><td><code>--fast</code></td>"+
+		"<td><code>[mph]</code> (optional)</td><td></td>"+
+		"<td>How *fast* to go</td></tr>\n")
+	assert.Contains(t, b.String(), "<td>The &lt;order&gt;</td>")
+	assert.Contains(t, b.String(), `<tr><td><a href="#prog-remote">`+
+		`<code>remote</code></a></td><td>Manage remotes</td></tr>`)
+	assert.Contains(t, b.String(), "<h2>prog remote</h2>\n")
+}
@@ -0,0 +1,197 @@
+package gotopt
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ParseResult is an index of the ParserStates of a ParseAll operation. Its
+// functions look up options in constant time by name, where a name is either
+// an option character of any integer type, ex. 'v' or byte('v'), or a long
+// name, ex. "verbose". A long name may
+// be given with its leading dashes, ex. "--verbose", and a string of a single
+// character preceded by one dash, ex. "-v", is an option character. A string
+// of a single character without a dash is a long name if one of the options
+// has that long name; otherwise it's an option character.
+//
+// The options of the commands invoked by the argument list are indexed along
+// with those of the parser, as are the options read from the environment and
+// configuration files and the options sent with their default values.
+type ParseResult struct {
+	shortOpts   map[int][]Option
+	longOpts    map[string][]Option
+	positionals []string
+	errs        []error
+}
+
+// NewParseResult returns a ParseResult for the list of ParserStates to which
+// ps belongs. The list must be complete, ex. the ParserState returned by
+// ParseAll. A nil ParserState returns an empty ParseResult.
+func NewParseResult(ps ParserState) *ParseResult {
+	r := &ParseResult{
+		shortOpts:   map[int][]Option{},
+		longOpts:    map[string][]Option{},
+		positionals: []string{},
+		errs:        []error{},
+	}
+	if ps == nil {
+		return r
+	}
+
+	for ps, ok := ps.First(), true; ok; ps, ok = ps.Next() {
+		switch tv := ps.Value().(type) {
+		case Option:
+			if opt := tv.Opt(); opt > 0 {
+				r.shortOpts[opt] = append(r.shortOpts[opt], tv)
+			}
+			if name := tv.LongName(); name != "" {
+				r.longOpts[name] = append(r.longOpts[name], tv)
+			}
+		case []string:
+			r.positionals = tv
+		case error:
+			r.errs = append(r.errs, tv)
+		}
+	}
+	return r
+}
+
+// ParseResult parses the supplied arguments and returns an index of the
+// resulting ParserStates.
+func (p *parser) ParseResult(argv []string) (*ParseResult, error) {
+	ps, err := p.ParseAll(argv)
+	if err != nil {
+		return nil, err
+	}
+	return NewParseResult(ps), nil
+}
+
+// lookup returns the options with the given name in the order in which they
+// were sent. The name may be an option character of any integer type, ex. a
+// rune or byte, or a string.
+func (r *ParseResult) lookup(name interface{}) []Option {
+	v := reflect.ValueOf(name)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return r.shortOpts[int(v.Int())]
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return r.shortOpts[int(v.Uint())]
+	case reflect.String:
+		tv := v.String()
+		switch {
+		case strings.HasPrefix(tv, "--"):
+			return r.longOpts[tv[2:]]
+		case strings.HasPrefix(tv, "-") && utf8.RuneCountInString(tv) == 2:
+			c, _ := utf8.DecodeRuneInString(tv[1:])
+			return r.shortOpts[int(c)]
+		}
+		if opts, ok := r.longOpts[tv]; ok {
+			return opts
+		}
+		if c, size := utf8.DecodeRuneInString(tv); size > 0 && size == len(tv) {
+			return r.shortOpts[int(c)]
+		}
+	}
+	return nil
+}
+
+// last returns the last of the options with the given name, or nil if no
+// option has the name.
+func (r *ParseResult) last(name interface{}) Option {
+	opts := r.lookup(name)
+	if len(opts) == 0 {
+		return nil
+	}
+	return opts[len(opts)-1]
+}
+
+// Has returns a flag indicating whether or not an option with the given name
+// was parsed.
+func (r *ParseResult) Has(name interface{}) bool {
+	return len(r.lookup(name)) > 0
+}
+
+// Get returns the value of the last option with the given name along with a
+// flag indicating whether or not the option was parsed.
+func (r *ParseResult) Get(name interface{}) (string, bool) {
+	o := r.last(name)
+	if o == nil {
+		return "", false
+	}
+	return o.Value(), true
+}
+
+// All returns the values of the options with the given name in the order in
+// which they were parsed.
+func (r *ParseResult) All(name interface{}) []string {
+	vals := []string{}
+	for _, o := range r.lookup(name) {
+		vals = append(vals, o.Value())
+	}
+	return vals
+}
+
+// Options returns the options with the given name in the order in which
+// they were parsed.
+func (r *ParseResult) Options(name interface{}) []Option {
+	return append([]Option{}, r.lookup(name)...)
+}
+
+// Count returns the Count of the last option with the given name, which is
+// the number of times the option was given or the level of a counting
+// option, or zero if the option was not parsed.
+func (r *ParseResult) Count(name interface{}) int {
+	o := r.last(name)
+	if o == nil {
+		return 0
+	}
+	return o.Count()
+}
+
+// Int returns the value of the last option with the given name as an int.
+// Zero is returned if the option was not parsed.
+func (r *ParseResult) Int(name interface{}) (int, error) {
+	s, ok := r.Get(name)
+	if !ok {
+		return 0, nil
+	}
+	v, err := strconv.ParseInt(s, 0, strconv.IntSize)
+	return int(v), err
+}
+
+// Bool returns the Bool of the last option with the given name, so a
+// negatable option given last in its negative form is false. False is
+// returned if the option was not parsed.
+func (r *ParseResult) Bool(name interface{}) bool {
+	o := r.last(name)
+	if o == nil {
+		return false
+	}
+	return o.Bool()
+}
+
+// Duration returns the value of the last option with the given name as a
+// time.Duration. Zero is returned if the option was not parsed.
+func (r *ParseResult) Duration(name interface{}) (time.Duration, error) {
+	s, ok := r.Get(name)
+	if !ok {
+		return 0, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// Positionals returns the non-option arguments that follow the options.
+func (r *ParseResult) Positionals() []string {
+	return r.positionals
+}
+
+// Errors returns the errors sent by the parse operation in the order in
+// which they were sent.
+func (r *ParseResult) Errors() []error {
+	return r.errs
+}
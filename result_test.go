@@ -0,0 +1,95 @@
+package gotopt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseResult(t *testing.T) {
+	p := NewParser()
+	p.Opt('n', "name", RequiredArgument, "", "")
+	p.Opt('t', "timeout", RequiredArgument, "", "", Default("5s"))
+	p.Opt(0, "color", NoArgument, "", "", Negatable())
+	p.Opt('v', "verbose", NoArgument, "", "", Counter())
+	p.Opt('x', "", NoArgument, "", "")
+	p.Opt(0, "j", RequiredArgument, "", "")
+
+	r, err := p.ParseResult([]string{
+		"tpresult01", "-n", "37", "--color", "-vvv", "--name=0x10",
+		"--no-color", "-f", "--j", "4", "effie", "play"})
+	assert.NoError(t, err)
+
+	assert.True(t, r.Has('n'))
+	assert.True(t, r.Has("name"))
+	assert.True(t, r.Has("--name"))
+	assert.True(t, r.Has("-n"))
+	assert.True(t, r.Has("n"))
+	assert.True(t, r.Has(byte('n')))
+	assert.True(t, r.Has(uint32('n')))
+	assert.True(t, r.Has(int64('n')))
+	assert.False(t, r.Has('x'))
+	assert.False(t, r.Has("x"))
+	assert.False(t, r.Has(3.14))
+
+	v, ok := r.Get('n')
+	assert.True(t, ok)
+	assert.Equal(t, "0x10", v)
+	assert.Equal(t, []string{"37", "0x10"}, r.All("name"))
+	assert.Equal(t, []string{}, r.All("missing"))
+	assert.Len(t, r.Options('n'), 2)
+
+	i, err := r.Int("name")
+	assert.NoError(t, err)
+	assert.Equal(t, 16, i)
+	i, err = r.Int('x')
+	assert.NoError(t, err)
+	assert.Equal(t, 0, i)
+
+	// "j" is the long name of an option, not an option character
+	v, _ = r.Get("j")
+	assert.Equal(t, "4", v)
+
+	d, err := r.Duration('t')
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, d)
+	_, err = r.Duration("name")
+	assert.Error(t, err)
+
+	assert.False(t, r.Bool("color"))
+	assert.True(t, r.Bool('v'))
+	assert.False(t, r.Bool('x'))
+	assert.Equal(t, 3, r.Count("verbose"))
+	assert.Equal(t, 2, r.Count('n'))
+	assert.Equal(t, 0, r.Count('x'))
+
+	assert.Equal(t, []string{"effie", "play"}, r.Positionals())
+	assert.Equal(t, []error{&ErrUnknownOpt{'f', ""}}, r.Errors())
+}
+
+func TestParseResultEmpty(t *testing.T) {
+	p := NewParser()
+	p.Opt('n', "name", NoArgument, "", "")
+
+	r, err := p.ParseResult([]string{"tpresult02"})
+	assert.NoError(t, err)
+	assert.False(t, r.Has('n'))
+	assert.Equal(t, []string{}, r.Positionals())
+	assert.Equal(t, []error{}, r.Errors())
+
+	_, err = p.ParseResult(nil)
+	assert.Equal(t, ErrEmptyArgList, err)
+}
+
+func TestParserStateLookupOptLong(t *testing.T) {
+	ps := testParseAll(
+		t, "tplookup01", "--time=37", "-n", "--time", "47", "effie")
+
+	for _, s := range []ParserState{ps.First(), ps.Last()} {
+		opts := s.LookupOptLong("time")
+		assert.Len(t, opts, 2)
+		assert.Equal(t, "47", opts[1].Value().(Option).Value())
+		assert.Len(t, s.LookupOpt('n'), 1)
+	}
+}
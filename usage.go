@@ -0,0 +1,98 @@
+package gotopt
+
+import (
+	"os"
+	"sort"
+	"strconv"
+)
+
+const (
+	// defaultUsageWidth is the width of the usage text when neither the
+	// parser nor the environment specifies one.
+	defaultUsageWidth = 80
+
+	// minUsageDescWidth is the minimum width to which descriptions in the
+	// usage text are wrapped.
+	minUsageDescWidth = 20
+)
+
+// SetUsageWidth sets the width to which the usage text is wrapped.
+func (p *parser) SetUsageWidth(width int) {
+	p.width = width
+}
+
+// usageWidth returns the width to which the usage text is wrapped.
+func (p *parser) usageWidth() int {
+	for c := p; c != nil; c = c.parent {
+		if c.width > 0 {
+			return c.width
+		}
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultUsageWidth
+}
+
+// SetUsageSort sets a flag indicating whether or not the usage text lists
+// the options in each group sorted by their long names.
+func (p *parser) SetUsageSort(sorted bool) {
+	p.sortUsage = sorted
+}
+
+// usageSorted returns a flag indicating whether or not the parser or any of
+// its ancestors sorts its options in the usage text.
+func (p *parser) usageSorted() bool {
+	for c := p; c != nil; c = c.parent {
+		if c.sortUsage {
+			return true
+		}
+	}
+	return false
+}
+
+// optGroup is a group of options listed under a heading in the usage text.
+// The options that are not in a group are in the group without a name.
+type optGroup struct {
+	name string
+	opts []*optDef
+}
+
+// usageGroups returns the options grouped as listed in the usage text. The
+// group without a name is first, followed by the named groups in the order
+// in which their first options appear in opts.
+func (p *parser) usageGroups(opts []*optDef) []*optGroup {
+	groups := []*optGroup{{}}
+	byName := map[string]*optGroup{"": groups[0]}
+	for _, o := range opts {
+		g, ok := byName[o.group]
+		if !ok {
+			g = &optGroup{name: o.group}
+			byName[o.group] = g
+			groups = append(groups, g)
+		}
+		g.opts = append(g.opts, o)
+	}
+	if len(groups[0].opts) == 0 {
+		groups = groups[1:]
+	}
+
+	if p.usageSorted() {
+		for _, g := range groups {
+			sort.SliceStable(g.opts, func(i, j int) bool {
+				return g.opts[i].sortKey() < g.opts[j].sortKey()
+			})
+		}
+	}
+
+	return groups
+}
+
+// sortKey returns the key by which the option is sorted in the usage text,
+// which is its long name, or its option character if it has no long name.
+func (o *optDef) sortKey() string {
+	if o.longName != "" {
+		return o.longName
+	}
+	return string(rune(o.opt))
+}
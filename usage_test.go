@@ -0,0 +1,147 @@
+package gotopt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintUsageWrap(t *testing.T) {
+	p := NewParser()
+	p.SetUsageWidth(50)
+	p.Opt('n', "name", NoArgument, "",
+		"A flag indicating the name is a trailing arg, which it is not always")
+	p.Opt('t', "time", RequiredArgument, "epoch",
+		"The epoch.\n\nThe time since the epoch began.")
+	p.Opt('x', "", NoArgument, "", "")
+	p.Command("remote", "Manage the set of remotes whose branches you track")
+
+	exp := `    -n, --name       A flag indicating the name is
+                     a trailing arg, which it is
+                     not always
+    -t, --time epoch The epoch.
+
+                     The time since the epoch
+                     began.
+    -x               
+
+    Commands:
+      remote         Manage the set of remotes
+                     whose branches you track
+`
+	assert.Equal(t, exp, p.Usage())
+}
+
+func TestPrintUsageWidthEnv(t *testing.T) {
+	defer setTestEnv("COLUMNS", "30")()
+
+	p := NewParser()
+	p.Opt('n', "name", NoArgument, "", "A flag indicating the name")
+	c := p.Command("remote", "")
+	c.Opt('v', "", NoArgument, "", "A flag indicating verbosity")
+
+	exp := `    -n, --name A flag indicating
+               the name
+
+    Commands:
+      remote   
+`
+	assert.Equal(t, exp, p.Usage())
+
+	p.SetUsageWidth(40)
+	exp = `    -v A flag indicating verbosity
+`
+	assert.Equal(t, exp, c.Usage())
+}
+
+func TestWrapText(t *testing.T) {
+	assert.Equal(t, []string{""}, wrapText("", 10))
+	assert.Equal(t, []string{"a b", "c"}, wrapText("a b c", 3))
+	assert.Equal(t, []string{"abcdef", "g"}, wrapText("abcdef g", 3))
+	assert.Equal(t, []string{"a", "", "b"}, wrapText("a\n\nb", 3))
+}
+
+func TestPrintUsageWideTerm(t *testing.T) {
+	p := NewParser()
+	p.SetUsageWidth(40)
+	p.Opt('n', "name", NoArgument, "", "The name")
+	p.Opt('t', "time-since-the-epoch", RequiredArgument, "seconds",
+		"The time since the epoch began")
+
+	exp := `    -n, --name The name
+    -t, --time-since-the-epoch seconds
+               The time since the epoch
+               began
+`
+	assert.Equal(t, exp, p.Usage())
+}
+
+func TestPrintUsageGroups(t *testing.T) {
+	p := NewParser()
+	p.Opt('v', "verbose", NoArgument, "", "Verbose output")
+	p.Opt('o', "output", RequiredArgument, "file", "The output file",
+		Group("Output options"))
+	p.Opt('i', "input", RequiredArgument, "file", "The input file",
+		Group("Input options"))
+	p.Opt('f', "format", RequiredArgument, "fmt", "The output format",
+		Group("Output options"))
+	p.Opt(0, "trace", NoArgument, "", "Trace execution",
+		Group("Debugging"))
+	p.Command("remote", "Manage remotes")
+
+	exp := `    -v, --verbose     Verbose output
+
+    Output options:
+    -o, --output file The output file
+    -f, --format fmt  The output format
+
+    Input options:
+    -i, --input file  The input file
+
+    Debugging:
+        --trace       Trace execution
+
+    Commands:
+      remote          Manage remotes
+`
+	assert.Equal(t, exp, p.Usage())
+
+	p.SetUsageSort(true)
+	exp = `    -v, --verbose     Verbose output
+
+    Output options:
+    -f, --format fmt  The output format
+    -o, --output file The output file
+
+    Input options:
+    -i, --input file  The input file
+
+    Debugging:
+        --trace       Trace execution
+
+    Commands:
+      remote          Manage remotes
+`
+	assert.Equal(t, exp, p.Usage())
+}
+
+func TestPrintUsageGroupsOnly(t *testing.T) {
+	p := NewParser()
+	p.SetUsageSort(true)
+	p.Opt('z', "", NoArgument, "", "Zero", Group("Misc"))
+	p.Opt('a', "all", NoArgument, "", "All", Group("Misc"))
+	c := p.Command("remote", "")
+	c.Opt('b', "bare", NoArgument, "", "Bare")
+	c.Opt('a', "add", NoArgument, "", "Add")
+
+	exp := `    Misc:
+    -a, --all All
+    -z        Zero
+`
+	assert.Equal(t, exp, p.Usage()[:len(exp)])
+
+	exp = `    -a, --add  Add
+    -b, --bare Bare
+`
+	assert.Equal(t, exp, c.Usage())
+}
@@ -3,45 +3,17 @@ package gotopt
 import (
 	"os"
 	"strings"
+	"unicode/utf8"
 )
 
-// strncmp compares up to num characters of the string str1 to those of the
-// string str2.
-//
-// This function starts comparing the first character of each string.
-// If they are equal to each other, it continues with the following pairs
-// until the characters differ, until a terminating null-character is reached,
-// or until num characters match in both strings, whichever happens first.
-//
-// If the first character that does not match has a lower value in str1 than
-// in str2 then a negative number is returned.
-//
-// If the contents of both strings are equal than zero is returned.
-//
-// If the first character that does not match has a greater value in str1 than
-// in str2 then a positive number is returned.
-func strncmp(str1, str2 string, num int) int {
-	lstr1 := len(str1)
-	lstr2 := len(str2)
-
-	for x := 0; x < num; x++ {
-		if x >= lstr1 || x >= lstr2 {
-			break
-		}
-		if str1[x] < str2[x] {
-			return -1
-		} else if str1[x] > str2[x] {
-			return 1
-		}
+// isLongOptPrefix returns a flag indicating whether or not the first n bytes
+// of arg are the long option name or an abbreviation of it. An abbreviation
+// must not end in the middle of one of the name's UTF-8 encoded characters.
+func isLongOptPrefix(name, arg string, n int) bool {
+	if n > len(name) || n > len(arg) || name[:n] != arg[:n] {
+		return false
 	}
-
-	return 0
-}
-
-// strncmpb is like strncmp except true or false is returned based on whether
-// strncmp would return that the strings are equal or different.
-func strncmpb(str1, str2 string, num int) bool {
-	return strncmp(str1, str2, num) == 0
+	return n == len(name) || utf8.RuneStart(name[n])
 }
 
 func envVarExists(name string) bool {
@@ -75,3 +47,26 @@ func parseLongOptSize(s string, nextChar int) (nameEnd, nameLen int) {
 
 	return
 }
+
+// wrapText wraps the text to lines no longer than width. Each line of the
+// text is wrapped separately, and words longer than width are not broken.
+func wrapText(s string, width int) []string {
+	lines := []string{}
+	for _, para := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+
+				utf8.RuneCountInString(word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,57 @@
+package gotopt
+
+// ValidateFunc is a function that validates the results of a Parse or
+// ParseAll operation.
+type ValidateFunc func(ps ParserState) error
+
+// Required marks an option as required. An ErrMissingOpt is sent for a
+// required option that is not parsed from the argument list, read from the
+// environment, read from a configuration file, or given a default value. An
+// experimental option is not required unless it's enabled.
+func Required() OptModifier {
+	return func(o *optDef) {
+		o.required = true
+	}
+}
+
+// Validate registers a function that is invoked once the arguments are
+// parsed.
+func (p *parser) Validate(f ValidateFunc) {
+	p.validators = append(p.validators, f)
+}
+
+// validate sends an ErrMissingOpt for each of the required options of the
+// invoked parsers that was not given, checks the parsers' constraints, and
+// then invokes the parsers' validation functions.
+//
+// The required options are checked once all of the arguments are parsed
+// since a persistent option may be given to any of the invoked commands.
+func (l *stateList) validate() {
+	for _, p := range l.parsers {
+		for _, o := range p.optsOrdered {
+			if !o.required || !o.enabled() {
+				continue
+			}
+			if _, ok := l.optIndices[o]; ok {
+				continue
+			}
+			l.send(&parserState{value: &ErrMissingOpt{o.opt, o.longName}})
+		}
+	}
+
+	l.checkConstraints()
+
+	for _, p := range l.parsers {
+		for _, f := range p.validators {
+			// ensure the list is traversable by the validation function
+			l.done()
+			var last ParserState
+			if l.prev != nil {
+				last = l.prev
+			}
+			if err := f(last); err != nil {
+				l.send(&parserState{value: err})
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package gotopt
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParserRequired(t *testing.T) {
+	p := NewParser()
+	p.Opt('n', "name", NoArgument, "", "", Required())
+	p.Opt('t', "time", RequiredArgument, "", "", Required())
+	p.Opt('x', "", RequiredArgument, "", "", Required())
+	p.Opt(0, "xist", RequiredArgument, "", "", Required())
+	p.Opt('y', "yes", RequiredArgument, "", "",
+		Required(), Default("play"))
+
+	ps, err := p.ParseAll([]string{"tprequired01", "-n", "effie"})
+	assert.NoError(t, err)
+
+	errs := []string{}
+	for ps = ps.First(); ps != nil; ps, _ = ps.Next() {
+		if e, ok := ps.Value().(*ErrMissingOpt); ok {
+			errs = append(errs, e.Error())
+		}
+	}
+	assert.Equal(t, []string{
+		"missing required option '-t, --time'",
+		"missing required option '-x'",
+		"missing required option '--xist'",
+	}, errs)
+}
+
+func TestParserRequiredPersistent(t *testing.T) {
+	p := NewParser()
+	p.Opt('t', "time", RequiredArgument, "", "", Required(), Persistent())
+	c := p.Command("run", "")
+	c.Opt('n', "name", NoArgument, "", "", Required())
+
+	ps, err := p.ParseAll([]string{"tprequired02", "run", "-t37"})
+	assert.NoError(t, err)
+	assert.IsType(t, &ErrMissingOpt{}, ps.Value())
+	assert.Equal(t, &ErrMissingOpt{'n', "name"}, ps.Value())
+	assert.Len(t, ps.LookupOpt('t'), 1)
+}
+
+func TestParserRequiredExperimental(t *testing.T) {
+	defer setTestEnv("GOTOPT_TEST_EXPERIMENTAL", "false")()
+
+	p := NewParser()
+	p.Opt('t', "time", RequiredArgument, "", "",
+		Required(), Experimental("GOTOPT_TEST_EXPERIMENTAL"))
+
+	ps, err := p.ParseAll([]string{"tprequired03", "effie"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"effie"}, ps.Value())
+	assert.Equal(t, 0, ps.Index())
+
+	os.Setenv("GOTOPT_TEST_EXPERIMENTAL", "true")
+	ps, err = p.ParseAll([]string{"tprequired03", "effie"})
+	assert.NoError(t, err)
+	assert.Equal(t, &ErrMissingOpt{'t', "time"}, ps.Value())
+}
+
+func TestParserValidate(t *testing.T) {
+	errBoth := errors.New("-a and -b are mutually exclusive")
+
+	p := NewParser()
+	p.Opt('a', "", NoArgument, "", "")
+	p.Opt('b', "", NoArgument, "", "")
+	p.Validate(func(ps ParserState) error {
+		if len(ps.LookupOpt('a')) > 0 && len(ps.LookupOpt('b')) > 0 {
+			return errBoth
+		}
+		return nil
+	})
+
+	ps, err := p.ParseAll([]string{"tpvalidate01", "-ab", "effie"})
+	assert.NoError(t, err)
+	assert.Equal(t, errBoth, ps.Value())
+	assert.Equal(t, ps, ps.Last())
+
+	ps, err = p.ParseAll([]string{"tpvalidate01", "-a", "effie"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"effie"}, ps.Value())
+
+	p = NewParser()
+	p.Validate(func(ps ParserState) error {
+		assert.Nil(t, ps)
+		return nil
+	})
+	_, err = p.ParseAll([]string{"tpvalidate02"})
+	assert.NoError(t, err)
+}
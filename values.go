@@ -0,0 +1,218 @@
+package gotopt
+
+import (
+	"strconv"
+	"time"
+)
+
+// Value is the interface to the dynamic value to which an option is bound.
+type Value interface {
+	// String returns the value as a string.
+	String() string
+
+	// Set parses the option's argument and stores the result.
+	Set(string) error
+}
+
+// boolFlag is implemented by Values that are set with the string "true" when
+// an option's optional argument is omitted.
+type boolFlag interface {
+	Value
+	IsBoolFlag() bool
+}
+
+// Var registers an option with the parser and binds it to the provided Value.
+func (p *parser) Var(
+	value Value,
+	opt int,
+	longName string,
+	optType OptionTypes,
+	argText, usage string,
+	mods ...OptModifier) {
+
+	p.opt(opt, longName, optType, argText, usage, mods...).value = value
+}
+
+// BoolVar registers an option and binds it to a bool variable.
+func (p *parser) BoolVar(
+	v *bool, opt int, longName, usage string, mods ...OptModifier) {
+
+	p.Var((*boolValue)(v), opt, longName, NoArgument, "", usage, mods...)
+}
+
+// StringVar registers an option and binds it to a string variable.
+func (p *parser) StringVar(
+	v *string, opt int, longName, argText, usage string,
+	mods ...OptModifier) {
+
+	p.Var((*stringValue)(v),
+		opt, longName, RequiredArgument, argText, usage, mods...)
+}
+
+// IntVar registers an option and binds it to an int variable.
+func (p *parser) IntVar(
+	v *int, opt int, longName, argText, usage string,
+	mods ...OptModifier) {
+
+	p.Var((*intValue)(v),
+		opt, longName, RequiredArgument, argText, usage, mods...)
+}
+
+// Int64Var registers an option and binds it to an int64 variable.
+func (p *parser) Int64Var(
+	v *int64, opt int, longName, argText, usage string,
+	mods ...OptModifier) {
+
+	p.Var((*int64Value)(v),
+		opt, longName, RequiredArgument, argText, usage, mods...)
+}
+
+// UintVar registers an option and binds it to a uint variable.
+func (p *parser) UintVar(
+	v *uint, opt int, longName, argText, usage string,
+	mods ...OptModifier) {
+
+	p.Var((*uintValue)(v),
+		opt, longName, RequiredArgument, argText, usage, mods...)
+}
+
+// Float64Var registers an option and binds it to a float64 variable.
+func (p *parser) Float64Var(
+	v *float64, opt int, longName, argText, usage string,
+	mods ...OptModifier) {
+
+	p.Var((*float64Value)(v),
+		opt, longName, RequiredArgument, argText, usage, mods...)
+}
+
+// DurationVar registers an option and binds it to a time.Duration variable.
+func (p *parser) DurationVar(
+	v *time.Duration, opt int, longName, argText, usage string,
+	mods ...OptModifier) {
+
+	p.Var((*durationValue)(v),
+		opt, longName, RequiredArgument, argText, usage, mods...)
+}
+
+// CountVar registers a counting option and binds it to an int variable.
+func (p *parser) CountVar(
+	v *int, opt int, longName, usage string, mods ...OptModifier) {
+
+	mods = append([]OptModifier{Counter()}, mods...)
+	p.Var((*countValue)(v), opt, longName, NoArgument, "", usage, mods...)
+}
+
+type boolValue bool
+
+func (b *boolValue) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*b = boolValue(v)
+	return nil
+}
+func (b *boolValue) String() string {
+	return strconv.FormatBool(bool(*b))
+}
+func (b *boolValue) IsBoolFlag() bool {
+	return true
+}
+
+type countValue int
+
+func (c *countValue) Set(s string) error {
+	if s == "" {
+		*c++
+		return nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*c = countValue(v)
+	return nil
+}
+func (c *countValue) String() string {
+	return strconv.Itoa(int(*c))
+}
+
+type stringValue string
+
+func (s *stringValue) Set(val string) error {
+	*s = stringValue(val)
+	return nil
+}
+func (s *stringValue) String() string {
+	return string(*s)
+}
+
+type intValue int
+
+func (i *intValue) Set(s string) error {
+	v, err := strconv.ParseInt(s, 0, strconv.IntSize)
+	if err != nil {
+		return err
+	}
+	*i = intValue(v)
+	return nil
+}
+func (i *intValue) String() string {
+	return strconv.Itoa(int(*i))
+}
+
+type int64Value int64
+
+func (i *int64Value) Set(s string) error {
+	v, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		return err
+	}
+	*i = int64Value(v)
+	return nil
+}
+func (i *int64Value) String() string {
+	return strconv.FormatInt(int64(*i), 10)
+}
+
+type uintValue uint
+
+func (i *uintValue) Set(s string) error {
+	v, err := strconv.ParseUint(s, 0, strconv.IntSize)
+	if err != nil {
+		return err
+	}
+	*i = uintValue(v)
+	return nil
+}
+func (i *uintValue) String() string {
+	return strconv.FormatUint(uint64(*i), 10)
+}
+
+type float64Value float64
+
+func (f *float64Value) Set(s string) error {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	*f = float64Value(v)
+	return nil
+}
+func (f *float64Value) String() string {
+	return strconv.FormatFloat(float64(*f), 'g', -1, 64)
+}
+
+type durationValue time.Duration
+
+func (d *durationValue) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = durationValue(v)
+	return nil
+}
+func (d *durationValue) String() string {
+	return time.Duration(*d).String()
+}
@@ -0,0 +1,168 @@
+package gotopt
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParserVars(t *testing.T) {
+	var (
+		n  bool
+		s  string
+		i  int
+		i6 int64
+		u  uint
+		f  float64
+		d  time.Duration
+	)
+	p := NewParser()
+	p.BoolVar(&n, 'n', "name", "")
+	p.StringVar(&s, 's', "str", "", "")
+	p.IntVar(&i, 'i', "int", "", "")
+	p.Int64Var(&i6, 0, "int64", "", "")
+	p.UintVar(&u, 'u', "", "", "")
+	p.Float64Var(&f, 'f', "float", "", "")
+	p.DurationVar(&d, 't', "time", "", "")
+
+	ps, err := p.ParseAll([]string{
+		"tpvars01", "-nseffie", "--int=-3", "--int64", "64",
+		"-u7", "--float=1.5", "effie", "-t", "37s"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"effie"}, ps.Last().Value())
+
+	assert.True(t, n)
+	assert.Equal(t, "effie", s)
+	assert.Equal(t, -3, i)
+	assert.EqualValues(t, 64, i6)
+	assert.EqualValues(t, 7, u)
+	assert.Equal(t, 1.5, f)
+	assert.Equal(t, 37*time.Second, d)
+}
+
+func TestParserVarsUnset(t *testing.T) {
+	s := "def"
+	i := 3
+	p := NewParser()
+	p.StringVar(&s, 's', "str", "", "")
+	p.IntVar(&i, 'i', "int", "", "")
+
+	_, err := p.ParseAll([]string{"tpvars02", "effie"})
+	assert.NoError(t, err)
+	assert.Equal(t, "def", s)
+	assert.Equal(t, 3, i)
+}
+
+func TestParserVarsInvalidArg(t *testing.T) {
+	var (
+		i int
+		d time.Duration
+	)
+	p := NewParser()
+	p.IntVar(&i, 'i', "", "", "")
+	p.DurationVar(&d, 0, "time", "", "")
+
+	ps, err := p.ParseAll([]string{"tpvars03", "-iabc", "--time=37", "-i3"})
+	assert.NoError(t, err)
+	ps = ps.First()
+
+	assert.IsType(t, &ErrInvalidArg{}, ps.Value())
+	e := ps.Value().(*ErrInvalidArg)
+	assert.EqualValues(t, 'i', e.Opt)
+	assert.Equal(t, "abc", e.Value)
+	assert.NotNil(t, e.Err)
+	assert.True(t, strings.HasPrefix(
+		e.Error(), "invalid arg 'abc' for option '-i': "))
+
+	ps, _ = ps.Next()
+	assert.IsType(t, &ErrInvalidArg{}, ps.Value())
+	e = ps.Value().(*ErrInvalidArg)
+	assert.Equal(t, "time", e.LongName)
+	assert.Equal(t, "37", e.Value)
+	assert.True(t, strings.HasPrefix(
+		e.Error(), "invalid arg '37' for option '--time': "))
+
+	ps, _ = ps.Next()
+	assert.Implements(t, (*Option)(nil), ps.Value())
+	o := ps.Value().(Option)
+	assert.Equal(t, 0, o.Index())
+	assert.Equal(t, 3, i)
+}
+
+type testListValue []string
+
+func (l *testListValue) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+func (l *testListValue) String() string {
+	return strings.Join(*l, ",")
+}
+
+func TestParserVarCustom(t *testing.T) {
+	l := &testListValue{}
+	p := NewParser()
+	p.Var(l, 'x', "xist", RequiredArgument, "", "")
+
+	_, err := p.ParseAll(
+		[]string{"tpvars04", "-xa", "--xist", "b", "effie", "--xist=c"})
+	assert.NoError(t, err)
+	assert.Equal(t, "a,b,c", l.String())
+}
+
+func TestParserCountVar(t *testing.T) {
+	var verbose int
+	p := NewParser()
+	p.CountVar(&verbose, 'v', "verbose", "Increase verbosity")
+	p.Opt('q', "quiet", NoArgument, "", "", Counter())
+	p.Opt('n', "name", NoArgument, "", "")
+
+	ps, err := p.ParseAll([]string{
+		"tpcount01", "-vvnv", "--verbose", "-qq", "--verbose=1", "-v", "-n"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, verbose)
+
+	counts := []int{}
+	for ps = ps.First(); ps != nil; ps, _ = ps.Next() {
+		counts = append(counts, ps.Value().(Option).Count())
+	}
+	assert.Equal(t, []int{1, 2, 1, 3, 4, 1, 2, 1, 2, 2}, counts)
+
+	qs := parseFirst(t, p, "tpcount02", "-qq", "--quiet=5", "-q").LookupOpt('q')
+	assert.Len(t, qs, 4)
+	assert.Equal(t, 6, qs[3].Value().(Option).Count())
+
+	ps, err = p.ParseAll([]string{"tpcount03", "--verbose=-1", "-v"})
+	assert.NoError(t, err)
+	ps = ps.First()
+	assert.IsType(t, &ErrInvalidArg{}, ps.Value())
+	ps, _ = ps.Next()
+	assert.Equal(t, 1, ps.Value().(Option).Count())
+
+	assert.Equal(t, `    -v, --verbose[=n] Increase verbosity
+    -q, --quiet[=n]   
+    -n, --name        
+`, p.Usage())
+}
+
+func TestPrintUsageCounter(t *testing.T) {
+	p := NewParser()
+	p.Opt('v', "verbose", NoArgument, "level", "Increase verbosity", Counter())
+	p.Opt('d', "debug", NoArgument, "[lvl]", "Debug output", Counter())
+	p.Opt('q', "", NoArgument, "", "Decrease verbosity", Counter())
+	p.Opt(0, "trace", NoArgument, "", "Trace output", Counter())
+
+	assert.Equal(t, `    -v, --verbose[=level] Increase verbosity
+    -d, --debug[=lvl]     Debug output
+    -q                    Decrease verbosity
+        --trace[=n]       Trace output
+`, p.Usage())
+}
+
+func parseFirst(t *testing.T, p Parser, argv ...string) ParserState {
+	ps, err := p.ParseAll(argv)
+	assert.NoError(t, err)
+	return ps.First()
+}